@@ -12,6 +12,8 @@ It has these top-level messages:
 	Nil
 	File
 	Issue
+	Fix
+	TextEdit
 	IssueRange
 	Position
 	Config
@@ -64,8 +66,12 @@ func (*Nil) ProtoMessage()    {}
 // File to be reviewed.
 type File struct {
 	// name of file.
-	Name  string  `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	Lines []int64 `protobuf:"varint,2,rep,name=lines" json:"lines,omitempty"`
+	Name        string  `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Lines       []int64 `protobuf:"varint,2,rep,name=lines" json:"lines,omitempty"`
+	Diff        string  `protobuf:"bytes,3,opt,name=diff" json:"diff,omitempty"`
+	BaseContent string  `protobuf:"bytes,4,opt,name=base_content" json:"base_content,omitempty"`
+	Content     []byte  `protobuf:"bytes,5,opt,name=content" json:"content,omitempty"`
+	Encoding    string  `protobuf:"bytes,6,opt,name=encoding" json:"encoding,omitempty"`
 }
 
 func (m *File) Reset()         { *m = File{} }
@@ -75,18 +81,22 @@ func (*File) ProtoMessage()    {}
 // Issue returned from a review.
 type Issue struct {
 	// The name of the issue.
-	Name      string            `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	Position  *IssueRange       `protobuf:"bytes,2,opt,name=position" json:"position,omitempty"`
-	Comment   string            `protobuf:"bytes,3,opt,name=comment" json:"comment,omitempty"`
-	CtxBefore string            `protobuf:"bytes,4,opt,name=ctxBefore" json:"ctxBefore,omitempty"`
-	LineText  string            `protobuf:"bytes,5,opt,name=lineText" json:"lineText,omitempty"`
-	CtxAfter  string            `protobuf:"bytes,6,opt,name=ctxAfter" json:"ctxAfter,omitempty"`
-	Metrics   map[string]string `protobuf:"bytes,7,rep,name=metrics" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Tags      []string          `protobuf:"bytes,8,rep,name=tags" json:"tags,omitempty"`
-	Link      string            `protobuf:"bytes,9,opt,name=link" json:"link,omitempty"`
-	NewCode   bool              `protobuf:"varint,10,opt,name=newCode" json:"newCode,omitempty"`
-	Patch     string            `protobuf:"bytes,11,opt,name=patch" json:"patch,omitempty"`
-	Err       string            `protobuf:"bytes,12,opt,name=err" json:"err,omitempty"`
+	Name        string            `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Position    *IssueRange       `protobuf:"bytes,2,opt,name=position" json:"position,omitempty"`
+	Comment     string            `protobuf:"bytes,3,opt,name=comment" json:"comment,omitempty"`
+	CtxBefore   string            `protobuf:"bytes,4,opt,name=ctxBefore" json:"ctxBefore,omitempty"`
+	LineText    string            `protobuf:"bytes,5,opt,name=lineText" json:"lineText,omitempty"`
+	CtxAfter    string            `protobuf:"bytes,6,opt,name=ctxAfter" json:"ctxAfter,omitempty"`
+	Metrics     map[string]string `protobuf:"bytes,7,rep,name=metrics" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tags        []string          `protobuf:"bytes,8,rep,name=tags" json:"tags,omitempty"`
+	Link        string            `protobuf:"bytes,9,opt,name=link" json:"link,omitempty"`
+	NewCode     bool              `protobuf:"varint,10,opt,name=newCode" json:"newCode,omitempty"`
+	Patch       string            `protobuf:"bytes,11,opt,name=patch" json:"patch,omitempty"`
+	Err         string            `protobuf:"bytes,12,opt,name=err" json:"err,omitempty"`
+	Fixes       []*Fix            `protobuf:"bytes,13,rep,name=fixes" json:"fixes,omitempty"`
+	Suppressed  bool              `protobuf:"varint,14,opt,name=suppressed" json:"suppressed,omitempty"`
+	Fingerprint string            `protobuf:"bytes,15,opt,name=fingerprint" json:"fingerprint,omitempty"`
+	Severity    string            `protobuf:"bytes,16,opt,name=severity" json:"severity,omitempty"`
 }
 
 func (m *Issue) Reset()         { *m = Issue{} }
@@ -107,6 +117,56 @@ func (m *Issue) GetMetrics() map[string]string {
 	return nil
 }
 
+func (m *Issue) GetFixes() []*Fix {
+	if m != nil {
+		return m.Fixes
+	}
+	return nil
+}
+
+// Fix is a named set of edits resolving an issue. The edits of one fix do
+// not overlap and should be applied together.
+type Fix struct {
+	Name  string      `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Edits []*TextEdit `protobuf:"bytes,2,rep,name=edits" json:"edits,omitempty"`
+}
+
+func (m *Fix) Reset()         { *m = Fix{} }
+func (m *Fix) String() string { return proto.CompactTextString(m) }
+func (*Fix) ProtoMessage()    {}
+
+func (m *Fix) GetEdits() []*TextEdit {
+	if m != nil {
+		return m.Edits
+	}
+	return nil
+}
+
+// TextEdit replaces the source between start and end with new_text.
+type TextEdit struct {
+	Start   *Position `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
+	End     *Position `protobuf:"bytes,2,opt,name=end" json:"end,omitempty"`
+	NewText string    `protobuf:"bytes,3,opt,name=new_text" json:"new_text,omitempty"`
+}
+
+func (m *TextEdit) Reset()         { *m = TextEdit{} }
+func (m *TextEdit) String() string { return proto.CompactTextString(m) }
+func (*TextEdit) ProtoMessage()    {}
+
+func (m *TextEdit) GetStart() *Position {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *TextEdit) GetEnd() *Position {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
 type IssueRange struct {
 	Start *Position `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
 	End   *Position `protobuf:"bytes,2,opt,name=end" json:"end,omitempty"`
@@ -176,6 +236,8 @@ type Info struct {
 	Metrics     []string  `protobuf:"bytes,6,rep,name=metrics" json:"metrics,omitempty"`
 	Language    string    `protobuf:"bytes,7,opt,name=language" json:"language,omitempty"`
 	Options     []*Option `protobuf:"bytes,8,rep,name=options" json:"options,omitempty"`
+	Locales     []string  `protobuf:"bytes,9,rep,name=locales" json:"locales,omitempty"`
+	Extensions  []string  `protobuf:"bytes,10,rep,name=extensions" json:"extensions,omitempty"`
 }
 
 func (m *Info) Reset()         { *m = Info{} }
@@ -200,6 +262,8 @@ func init() {
 	proto.RegisterType((*Nil)(nil), "api.Nil")
 	proto.RegisterType((*File)(nil), "api.File")
 	proto.RegisterType((*Issue)(nil), "api.Issue")
+	proto.RegisterType((*Fix)(nil), "api.Fix")
+	proto.RegisterType((*TextEdit)(nil), "api.TextEdit")
 	proto.RegisterType((*IssueRange)(nil), "api.IssueRange")
 	proto.RegisterType((*Position)(nil), "api.Position")
 	proto.RegisterType((*Config)(nil), "api.Config")
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"github.com/lingo-reviews/tenets/go/dev/api"
 	"github.com/lingo-reviews/tenets/go/dev/tenet"
@@ -23,9 +25,12 @@ func (s *server) GetInfo(_ context.Context, _ *api.Nil) (*api.Info, error) {
 	return tenet.APIInfo(i), nil
 }
 
-// Options are passed in via .lingo or on the CLI.
+// Options are passed in via .lingo or on the CLI. If any are rejected, the
+// error lists them with the options the tenet has.
 func (s *server) Configure(_ context.Context, cfg *api.Config) (*api.Nil, error) {
-	s.tenet.(tenet.BaseTenet).MixinConfigOptions(cfg.Options)
+	if err := s.tenet.(tenet.BaseTenet).MixinConfigOptions(cfg.Options); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+	}
 	return &api.Nil{}, nil
 }
 
@@ -18,10 +18,14 @@ func APIInfo(i *Info) *api.Info {
 		Tags:        i.tags,
 		Metrics:     i.metrics,
 		Language:    i.Language,
+		Locales:     i.locales,
+		Extensions:  i.Extensions,
 	}
 
-	apiInfo.Options = make([]*api.Option, len(i.Options))
-	for i, o := range i.Options {
+	// Framework options are listed after the tenet's own.
+	options := append(append([]*option(nil), i.Options...), i.frameworkOptions...)
+	apiInfo.Options = make([]*api.Option, len(options))
+	for i, o := range options {
 		apiInfo.Options[i] = &api.Option{
 			Name:  o.name,
 			Usage: o.usage,
@@ -34,17 +38,21 @@ func APIInfo(i *Info) *api.Info {
 
 func APIIssue(i *Issue) *api.Issue {
 	issue := &api.Issue{
-		Name:      i.Filename(),
-		Position:  apiIssueRange(i.Position),
-		Comment:   i.Comment,
-		CtxBefore: i.CtxBefore,
-		LineText:  i.LineText,
-		CtxAfter:  i.CtxAfter,
-		Link:      i.Link,
-		Metrics:   apiMetrics(i.Metrics),
-		Tags:      i.Tags,
-		NewCode:   i.NewCode,
-		Patch:     i.Patch,
+		Name:        i.Filename(),
+		Position:    apiIssueRange(i.Position),
+		Comment:     i.Comment,
+		CtxBefore:   i.CtxBefore,
+		LineText:    i.LineText,
+		CtxAfter:    i.CtxAfter,
+		Link:        i.Link,
+		Metrics:     apiMetrics(i.Metrics),
+		Tags:        i.Tags,
+		NewCode:     i.NewCode,
+		Patch:       i.Patch,
+		Fixes:       apiFixes(i.Fixes),
+		Suppressed:  i.Suppressed,
+		Fingerprint: i.Fingerprint,
+		Severity:    string(i.Severity),
 	}
 	if i.Err != nil {
 		issue.Err = i.Err.Error()
@@ -61,6 +69,22 @@ func apiMetrics(oldMap map[string]interface{}) map[string]string {
 	return newMap
 }
 
+func apiFixes(fixes []*IssueFix) []*api.Fix {
+	var apiFixes []*api.Fix
+	for _, f := range fixes {
+		fix := &api.Fix{Name: f.Name}
+		for _, e := range f.Edits {
+			fix.Edits = append(fix.Edits, &api.TextEdit{
+				Start:   apiPosition(e.Range.Start),
+				End:     apiPosition(e.Range.End),
+				NewText: e.NewText,
+			})
+		}
+		apiFixes = append(apiFixes, fix)
+	}
+	return apiFixes
+}
+
 func apiIssueRange(r *issueRange) *api.IssueRange {
 	return &api.IssueRange{
 		Start: apiPosition(r.Start),
@@ -5,8 +5,12 @@ package tenet
 
 import (
 	"fmt"
+	"go/ast"
 	"go/token"
+	"reflect"
 	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/juju/errors"
 	"github.com/lingo-reviews/tenets/go/dev/api"
@@ -19,6 +23,9 @@ type Base struct {
 	// all issues this tenet looks for.
 	registeredIssues map[string]*Issue
 
+	// frameworkIssues are raised by every tenet, such as syntax_error.
+	frameworkIssues map[string]*Issue
+
 	// all issues this tenet found.
 	issuesc chan *Issue
 
@@ -30,27 +37,44 @@ type Base struct {
 	// tmpdir is the dir for tenets to work in.
 	tmpdir string
 
-	astVisitors  astVisitors
-	lineVisitors lineVisitors
+	astVisitors     astVisitors
+	lineVisitors    lineVisitors
+	packageVisitors packageVisitors
+
+	// astDispatch maps node types to astVisitors. It is rebuilt when a
+	// visitor is added.
+	astDispatch *nodeDispatch
+
+	// workers is the number of files reviewed at once.
+	workers int
+
+	// reportSuppressed is the report_suppressed framework option.
+	reportSuppressed *bool
+
+	// baselinePath and writeBaselinePath are the baseline and
+	// write_baseline framework options.
+	baselinePath      *string
+	writeBaselinePath *string
+
+	// locale is the locale framework option.
+	locale *string
+
+	// newCodeOnly and newCodeRadius are the new_code_only and
+	// new_code_radius framework options.
+	newCodeOnly   *bool
+	newCodeRadius *int
+
+	// templateFuncs are the tenet's own comment template functions.
+	templateFuncs template.FuncMap
 
 	info *Info
 }
 
 type astVisitors []astVisitor
 
-func (p *astVisitors) deleteRange(a, z int) {
-	s := *p
-	s = append(s[:a], s[z+1:]...)
-	*p = s
-}
-
 type lineVisitors []lineVisitor
 
-func (p *lineVisitors) deleteRange(a, z int) {
-	s := *p
-	s = append(s[:a], s[z+1:]...)
-	*p = s
-}
+type packageVisitors []packageVisitor
 
 // base allows us to access the base struct when it's embeded in another
 // struct with a Tenet interface type.
@@ -67,12 +91,22 @@ func (b *Base) Init() {
 }
 
 func (b *Base) NewReview() *review {
+	// A tenet without Info still raises the framework issues.
+	if b.frameworkIssues == nil {
+		b.registerFrameworkIssues()
+	}
 	r := &review{
 		tenet:       b,
 		issuesc:     make(chan *Issue),
 		filesc:      make(chan *api.File),
 		waitc:       make(chan struct{}),
 		fileDoneMap: map[string]bool{},
+		fset:        token.NewFileSet(),
+		asts:        map[string]*ast.File{},
+		packages:    map[string]*gopackage{},
+		mu:          &sync.Mutex{},
+		tmpdir:      new(string),
+		found:       &baseline{},
 	}
 	go func() {
 		<-r.waitc
@@ -100,43 +134,164 @@ func (b *Base) Errors() chan error {
 }
 
 func (b *Base) SmellNode(f smellNodeFunc) Tenet {
-	b.astVisitors = append(b.astVisitors, astVisitor{
-		smellNode: f,
-		fileDone:  map[string]bool{},
-	})
+	b.addASTVisitor(reflectASTVisitor(f))
 	return b
 }
 
+// OnNode will smell every node of type T, like SmellNode, but the signature
+// of f is checked by the compiler. If T is an interface, such as ast.Expr,
+// every node implementing it is smelt, including those nested in another.
+// e.g.
+//
+//	tenet.OnNode(t, func(r tenet.Review, fnc *ast.FuncDecl) error {
+//		...
+//	})
+func OnNode[T ast.Node](t Tenet, f func(Review, T) error) Tenet {
+	base(t).addASTVisitor(newASTVisitor(reflect.TypeOf((*T)(nil)).Elem(), func(r Review, node ast.Node) error {
+		return f(r, node.(T))
+	}))
+	return t
+}
+
+func (b *Base) addASTVisitor(v astVisitor) {
+	b.astVisitors = append(b.astVisitors, v)
+	b.astDispatch = nil
+}
+
+func (b *Base) nodeDispatch() *nodeDispatch {
+	if b.astDispatch == nil {
+		b.astDispatch = newNodeDispatch(b.astVisitors)
+	}
+	return b.astDispatch
+}
+
 func (b *Base) SmellLine(f smellLineFunc) Tenet {
 	b.lineVisitors = append(b.lineVisitors, lineVisitor{
-		visit:    f,
-		fileDone: map[string]bool{},
+		visit: f,
 	})
 	return b
 }
 
+func (b *Base) SmellPackage(f smellPackageFunc) Tenet {
+	b.packageVisitors = append(b.packageVisitors, packageVisitor{
+		visit: f,
+	})
+	return b
+}
+
+// ReviewConcurrently reviews up to workers files at once. Only use this if
+// every smell of the tenet is safe to call concurrently. Issues are still
+// sent in the order the files were sent, with the same comment contexts as a
+// sequential review.
+func (b *Base) ReviewConcurrently(workers int) Tenet {
+	b.workers = workers
+	return b
+}
+
+// MixinConfigOptions sets the options passed in by the user. Every option is
+// tried, even after one is rejected. The error lists every rejected option
+// along with the options the tenet has.
 func (b *Base) MixinConfigOptions(opts []*api.Option) error {
+	if len(opts) == 0 {
+		return nil
+	}
+	if b.info == nil {
+		names := make([]string, len(opts))
+		for i, opt := range opts {
+			names[i] = fmt.Sprintf("%q", opt.Name)
+		}
+		return errors.Errorf("cannot set options %s before the tenet's info is set: the tenet must call SetInfo first", strings.Join(names, ", "))
+	}
+
+	var rejected []error
 	for _, opt := range opts {
 		if err := b.setOpt(opt); err != nil {
-			return errors.Trace(err)
+			rejected = append(rejected, err)
 		}
 	}
+	if len(rejected) > 0 {
+		return &configError{rejected: rejected, info: b.info}
+	}
 	return nil
 }
 
+// configError lists the options rejected by MixinConfigOptions.
+type configError struct {
+	rejected []error
+	info     *Info
+}
+
+func (e *configError) Error() string {
+	var lines []string
+	for _, err := range e.rejected {
+		lines = append(lines, err.Error())
+	}
+	lines = append(lines, "valid options are:")
+	for _, options := range [][]*option{e.info.Options, e.info.frameworkOptions} {
+		for _, opt := range options {
+			lines = append(lines, fmt.Sprintf("  %s: %s", opt.name, opt.usage))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (b *Base) setOpt(opt *api.Option) error {
 	if b.info == nil {
 		return errors.New("tenet info is nil")
 	}
-	for _, bOpt := range b.info.Options {
-		if bOpt.name == opt.Name {
-			*bOpt.value = opt.Value
-			return nil
+	for _, options := range [][]*option{b.info.Options, b.info.frameworkOptions} {
+		for _, bOpt := range options {
+			if bOpt.name == opt.Name {
+				if bOpt.parse != nil {
+					if err := bOpt.parse(opt.Value); err != nil {
+						return errors.Annotatef(err, "invalid value for option %q", opt.Name)
+					}
+				}
+				*bOpt.value = opt.Value
+				return nil
+			}
 		}
 	}
+	if strings.HasPrefix(opt.Name, commentOptionPrefix) {
+		return errors.Annotatef(b.setCommentOpt(opt), "invalid value for option %q", opt.Name)
+	}
 	return errors.Errorf("tenet has no option %q", opt.Name)
 }
 
+// commentOptionPrefix prefixes the options which override the comment of an
+// issue, e.g. "comment.unused_func_arg.first".
+const commentOptionPrefix = "comment."
+
+// setCommentOpt sets the comment template of an issue in a context, given an
+// option named "comment.<issue>.<context>". The template is parsed when the
+// option is set, so that a bad template is reported then. It is only executed
+// when the issue is raised, with the issue's comment variables. An empty
+// template removes the override.
+func (b *Base) setCommentOpt(opt *api.Option) error {
+	name := strings.TrimPrefix(opt.Name, commentOptionPrefix)
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return errors.Errorf("expected an option named %s<issue>.<context>", commentOptionPrefix)
+	}
+	issueName, ctxName := name[:dot], name[dot+1:]
+
+	issue := b.issue(issueName)
+	if issue == nil {
+		return errors.Errorf("tenet has no issue %q", issueName)
+	}
+	ctx, ok := commentContextNames[ctxName]
+	if !ok {
+		return errors.Errorf("unknown comment context %q, expected one of %s", ctxName, commentContextNamesList())
+	}
+	if opt.Value != "" {
+		if _, err := b.parseComment(opt.Value); err != nil {
+			return errors.Annotate(err, "bad comment template")
+		}
+	}
+	issue.setConfiguredComment(opt.Value, ctx)
+	return nil
+}
+
 func AddComment(comment string, ctx ...CommentContext) RegisterIssueOption {
 	return func(issue *Issue) {
 		issue.addComment(comment, ctx...)
@@ -147,10 +302,20 @@ type option struct {
 	name  string
 	value *string
 	usage string
+
+	// parse, if set, parses the value set by the user into a typed option,
+	// or rejects it.
+	parse func(value string) error
 }
 
-// TODO(waigani) support interface values
+// RegisterOption registers an option the user can set, returning a pointer to
+// its value. See also the typed RegisterIntOption, RegisterFloatOption,
+// RegisterBoolOption, RegisterStringListOption and RegisterRegexOption.
 func (b *Base) RegisterOption(name string, value string, usage string) *string {
+	return b.registerOption(name, value, usage, nil)
+}
+
+func (b *Base) registerOption(name string, value string, usage string, parse func(string) error) *string {
 
 	// toml doesn't support "-"
 	blacklistChars := "- "
@@ -169,6 +334,7 @@ func (b *Base) RegisterOption(name string, value string, usage string) *string {
 		name:  name,
 		value: v,
 		usage: usage,
+		parse: parse,
 	})
 
 	// return the value from the pointer for this option which will either be the
@@ -176,6 +342,61 @@ func (b *Base) RegisterOption(name string, value string, usage string) *string {
 	return v
 }
 
+// registerFrameworkOptions registers the options every tenet has. It is
+// called when the tenet's Info is set.
+func (b *Base) registerFrameworkOptions() {
+	b.reportSuppressed = new(bool)
+	b.registerFrameworkOption("report_suppressed", "false",
+		"If true, issues suppressed by a lingo:ignore comment are reported as suppressed, rather than dropped.", parseBool(b.reportSuppressed))
+	b.baselinePath = b.registerFrameworkOption("baseline", "",
+		"The path of a baseline file. Issues in the baseline are not raised.", nil)
+	b.writeBaselinePath = b.registerFrameworkOption("write_baseline", "",
+		"If set, a baseline of every issue found is written to this path at the end of the review.", nil)
+	b.locale = b.registerFrameworkOption("locale", "",
+		"The locale of comments, e.g. fr or pt-BR. Comments fall back to the default language if the tenet has none in this locale.", nil)
+	b.newCodeOnly = new(bool)
+	b.registerFrameworkOption("new_code_only", "false",
+		"If true, only issues in or near lines added by the change under review are raised. Files sent without a diff are reviewed in full.", parseBool(b.newCodeOnly))
+	b.newCodeRadius = new(int)
+	b.registerFrameworkOption("new_code_radius", "0",
+		"With new_code_only, issues up to this many lines from an added line are also raised.", parseRadius(b.newCodeRadius))
+	b.registerFrameworkOption("include_files", strings.Join(b.info.IncludeFiles, ","),
+		"A comma separated list of globs, e.g. *_test.go. If set, only files matching one of them are reviewed.", parseGlobs(&b.info.IncludeFiles))
+	b.registerFrameworkOption("exclude_files", strings.Join(b.info.ExcludeFiles, ","),
+		"A comma separated list of globs, e.g. vendor,*_mock.go. Files matching any of them, or in a dir matching any of them, are not reviewed.", parseGlobs(&b.info.ExcludeFiles))
+}
+
+// reportsSuppressed returns true if suppressed issues should be reported.
+func (b *Base) reportsSuppressed() bool {
+	return b.reportSuppressed != nil && *b.reportSuppressed
+}
+
+// writesBaseline returns true if a baseline is written at the end of the
+// review.
+func (b *Base) writesBaseline() bool {
+	return b.writeBaselinePath != nil && *b.writeBaselinePath != ""
+}
+
+// registerFrameworkOption registers a framework option, replacing any
+// already registered with the same name.
+func (b *Base) registerFrameworkOption(name string, value string, usage string, parse func(string) error) *string {
+	v := &value
+	opt := &option{
+		name:  name,
+		value: v,
+		usage: usage,
+		parse: parse,
+	}
+	for i, o := range b.info.frameworkOptions {
+		if o.name == name {
+			b.info.frameworkOptions[i] = opt
+			return v
+		}
+	}
+	b.info.frameworkOptions = append(b.info.frameworkOptions, opt)
+	return v
+}
+
 // RegisterMetric registers a metric key name that can be used when raising an issue.
 func (b *Base) RegisterMetric(key string) func(val interface{}) RaiseIssueOption {
 	b.info.metrics = append(b.info.metrics, key)
@@ -202,9 +423,20 @@ func (b *Base) RegisterTag(tag string) RaiseIssueOption {
 }
 
 func (b *Base) RegisterIssue(issueName string, opts ...RegisterIssueOption) string {
+	if b.registeredIssues == nil {
+		b.registeredIssues = map[string]*Issue{}
+	}
+	b.registeredIssues[issueName] = b.newIssue(issueName, opts...)
+
+	return issueName
+}
+
+// newIssue returns an issue which can be raised by the tenet.
+func (b *Base) newIssue(issueName string, opts ...RegisterIssueOption) *Issue {
 	issue := &Issue{
 		Name:     issueName,
 		CommVars: map[string]interface{}{},
+		Severity: SeverityWarning,
 	}
 
 	for _, opt := range opts {
@@ -216,12 +448,37 @@ func (b *Base) RegisterIssue(issueName string, opts ...RegisterIssueOption) stri
 		issue.addComment("Issue Found")
 	}
 
-	if b.registeredIssues == nil {
-		b.registeredIssues = map[string]*Issue{}
+	if b.info != nil {
+		b.info.addLocales(issue)
+		// Let the user override the severity.
+		issue.severity = b.registerFrameworkOption("severity."+issueName, string(issue.Severity),
+			fmt.Sprintf("The severity of %q issues: error, warning, info or hint.", issueName), validateSeverity)
+	}
+	return issue
+}
+
+// syntaxErrorIssue is raised by every tenet for each syntax error in a Go
+// file.
+const syntaxErrorIssue = "syntax_error"
+
+// registerFrameworkIssues registers the issues every tenet raises. Unlike
+// registered issues, their comment contexts do not keep a review open. It is
+// called when the tenet's Info is set, or by NewReview if it never was.
+func (b *Base) registerFrameworkIssues() {
+	b.frameworkIssues = map[string]*Issue{
+		syntaxErrorIssue: b.newIssue(syntaxErrorIssue,
+			AddComment("syntax error: {{.error}}"),
+			Severity(SeverityError),
+		),
 	}
-	b.registeredIssues[issueName] = issue
+}
 
-	return issueName
+// issue returns the registered or framework issue named issueName, or nil.
+func (b *Base) issue(issueName string) *Issue {
+	if i, ok := b.registeredIssues[issueName]; ok {
+		return i
+	}
+	return b.frameworkIssues[issueName]
 }
 
 type errWithContext struct {
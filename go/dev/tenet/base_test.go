@@ -1,6 +1,19 @@
 package tenet_test
 
 import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	gc "gopkg.in/check.v1"
@@ -240,7 +253,7 @@ func (s *baseSuite) TestRegisterOption(c *gc.C) {
 	}}
 
 	err := b.MixinConfigOptions(opts)
-	c.Assert(err, gc.ErrorMatches, `tenet has no option \"key\"`)
+	c.Assert(err, gc.ErrorMatches, `tenet has no option \"key\"\nvalid options are:\n(?s:.*)`)
 
 	v := b.RegisterOption("key", "default", "usage string")
 	c.Assert(*v, gc.Equals, "default")
@@ -361,3 +374,1626 @@ package secondMock
 	// a file, we can set DefaultComment|FileContext as matched
 	s.assertCxtFull(c, false)
 }
+
+func (s *baseSuite) TestPackageTypesInfo(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("const_use", tenet.AddComment("{{.name}} is of type {{.type}}"))
+
+	// Raise an issue for every constant used, resolving its type with the
+	// package's type information.
+	b.SmellNode(func(r tenet.Review, ident *ast.Ident) error {
+		obj, ok := r.Package().TypesInfo().Uses[ident].(*types.Const)
+		if !ok {
+			return nil
+		}
+		r.RaiseNodeIssue("const_use", ident,
+			tenet.CommentVar("name", obj.Name()),
+			tenet.CommentVar("type", types.TypeString(obj.Type(), types.RelativeTo(r.Package().Types()))),
+		)
+		return nil
+	})
+
+	// The constant and its type are declared in another file of the
+	// package.
+	files := []string{
+		s.TmpFile(c, "package mock\n\nvar x = Answer\n"),
+		s.TmpFile(c, "package mock\n\ntype number int\n\nconst Answer number = 42\n"),
+	}
+
+	s.CheckFiles(c, files, tt.ExpectedIssue{
+		Text:     "var x = Answer",
+		Comment:  "Answer is of type number",
+		Filename: files[0],
+	})
+	c.Assert(s.Review.Package(), gc.NotNil)
+}
+
+func (s *baseSuite) TestSmellPackage(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("receiver_name", tenet.AddComment("receiver should be named {{.name}}"))
+
+	// Check receiver names are consistent across all files of a package.
+	b.SmellPackage(func(r tenet.Review, p tenet.Package) error {
+		var name string
+		for _, f := range p.Files() {
+			for _, decl := range f.AST().Decls {
+				fnc, ok := decl.(*ast.FuncDecl)
+				if !ok || fnc.Recv == nil {
+					continue
+				}
+				recv := fnc.Recv.List[0].Names[0]
+				if name == "" {
+					name = recv.Name
+				} else if recv.Name != name {
+					r.RaiseNodeIssue("receiver_name", recv, tenet.CommentVar("name", name))
+				}
+			}
+		}
+		return nil
+	})
+
+	files := []string{
+		s.TmpFile(c, "package mock\n\ntype T struct{}\n\nfunc (t T) A() {}\n"),
+		s.TmpFile(c, "package mock\n\nfunc (x T) B() {}\n"),
+	}
+
+	s.CheckFiles(c, files, tt.ExpectedIssue{
+		Text:     "func (x T) B() {}",
+		Comment:  "receiver should be named t",
+		Filename: files[1],
+	})
+}
+
+func (s *baseSuite) TestSmellPackageDoneOnlyLastsForReview(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("package_found")
+	b.SmellPackage(func(r tenet.Review, p tenet.Package) error {
+		r.RaiseLineIssue("package_found", 1, 1)
+		r.SmellDone()
+		return nil
+	})
+
+	expected := tt.ExpectedIssue{
+		Text:    "package mock",
+		Comment: "Issue Found",
+	}
+	s.CheckSRC(c, "package mock", expected)
+
+	s.Review = b.NewReview()
+	s.CheckSRC(c, "package mock", expected)
+}
+
+func (s *baseSuite) TestNestedSmellInSmellPackagePanics(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.SmellPackage(func(r tenet.Review, p tenet.Package) error {
+		c.Check(func() {
+			r.SmellLine(func(r tenet.Review, n int, line []byte) error { return nil })
+		}, gc.PanicMatches, `SmellLine cannot be called from a package smell`)
+		c.Check(func() {
+			r.SmellNode(func(r tenet.Review, n *ast.Ident) error { return nil })
+		}, gc.PanicMatches, `SmellNode cannot be called from a package smell`)
+		return nil
+	})
+
+	s.CheckSRC(c, "package mock")
+}
+
+func (s *baseSuite) TestNestedSmellIsScopedToFile(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("deprecated_call", tenet.AddComment("{{.name}} is deprecated"))
+
+	// Find deprecated funcs, then look for calls to them in a second pass
+	// over the same file.
+	b.SmellNode(func(r tenet.Review, fnc *ast.FuncDecl) error {
+		if fnc.Doc == nil || !strings.HasPrefix(fnc.Doc.Text(), "Deprecated") {
+			return nil
+		}
+		name := fnc.Name.Name
+		r.SmellNode(func(r tenet.Review, call *ast.CallExpr) error {
+			if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == name {
+				r.RaiseNodeIssue("deprecated_call", call, tenet.CommentVar("name", name))
+			}
+			return nil
+		})
+		return nil
+	})
+
+	files := []string{
+		s.TmpFile(c, `
+package mock
+
+// Deprecated: use new.
+func old() {}
+
+func f() {
+	old()
+}
+`[1:]),
+		// old is not declared in this file, so the nested smell is not run.
+		s.TmpFile(c, `
+package mock
+
+func g() {
+	old()
+}
+`[1:]),
+	}
+
+	s.CheckFiles(c, files, tt.ExpectedIssue{
+		Text:     "\told()",
+		Comment:  "old is deprecated",
+		Filename: files[0],
+	})
+}
+
+func (s *baseSuite) TestOnNode(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("func_decl", tenet.AddComment("func {{.name}}"))
+	b.RegisterIssue("basic_lit", tenet.AddComment("literal {{.value}}"))
+
+	tenet.OnNode(b, func(r tenet.Review, fnc *ast.FuncDecl) error {
+		r.RaiseNodeIssue("func_decl", fnc.Name, tenet.CommentVar("name", fnc.Name.Name))
+		return nil
+	})
+
+	// Every node implementing an interface can be smelt.
+	tenet.OnNode(b, func(r tenet.Review, expr ast.Expr) error {
+		if lit, ok := expr.(*ast.BasicLit); ok {
+			r.RaiseNodeIssue("basic_lit", lit, tenet.CommentVar("value", lit.Value))
+		}
+		return nil
+	})
+
+	// The literals are nested in a call and a binary expression, which are
+	// expressions too.
+	s.CheckSRC(c, "package mock\n\nfunc f(a, b int) {}\n\nvar x = f(1, 2+3)\n", []tt.ExpectedIssue{
+		{
+			Text:    "func f(a, b int) {}",
+			Comment: "func f",
+		}, {
+			Text:    "var x = f(1, 2+3)",
+			Comment: "literal 1",
+		}, {
+			Text:    "var x = f(1, 2+3)",
+			Comment: "literal 2",
+		}, {
+			Text:    "var x = f(1, 2+3)",
+			Comment: "literal 3",
+		}}...)
+}
+
+func (s *baseSuite) TestSmellNodeBadSignature(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	c.Assert(func() {
+		b.SmellNode(func(r tenet.Review, n int) error { return nil })
+	}, gc.PanicMatches, `SmellNode expects a func\(tenet.Review, <ast.Node>\) error, got func\(tenet.Review, int\) error`)
+}
+
+func (s *baseSuite) TestSmellsAreCalledInRegistrationOrder(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("called", tenet.AddComment("{{.name}} is called"))
+
+	// The first smell sees every call in the file before the second smell
+	// is called. As with ast.Walk, calls nested in a smelt call are skipped.
+	called := map[string]bool{}
+	b.SmellNode(func(r tenet.Review, call *ast.CallExpr) error {
+		called[call.Fun.(*ast.Ident).Name] = true
+		return nil
+	})
+
+	b.SmellNode(func(r tenet.Review, fnc *ast.FuncDecl) error {
+		if called[fnc.Name.Name] {
+			r.RaiseNodeIssue("called", fnc.Name, tenet.CommentVar("name", fnc.Name.Name))
+		}
+		return nil
+	})
+
+	s.CheckSRC(c, `
+package mock
+
+func a(int) {}
+
+func b() int { return 1 }
+
+func main() { a(b()) }
+`[1:], tt.ExpectedIssue{
+		Text:    "func a(int) {}",
+		Comment: "a is called",
+	})
+}
+
+func (s *baseSuite) TestReviewConcurrently(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+	b.ReviewConcurrently(4)
+
+	b.RegisterIssue("todo",
+		tenet.AddComment("first todo", tenet.FirstComment),
+		tenet.AddComment("second todo", tenet.SecondComment),
+		tenet.AddComment("todo in third file", tenet.InThirdFile),
+	)
+
+	b.SmellNode(func(r tenet.Review, comment *ast.Comment) error {
+		if strings.Contains(comment.Text, "TODO") {
+			r.RaiseNodeIssue("todo", comment)
+		}
+		return nil
+	})
+
+	var files []string
+	for i := 0; i < 8; i++ {
+		files = append(files, s.TmpFile(c, fmt.Sprintf("package mock\n\n// TODO %d\nfunc f() {}\n", i)))
+	}
+
+	// Issues come back in file order, with the contexts of a sequential
+	// review.
+	s.CheckFiles(c, files, []tt.ExpectedIssue{
+		{
+			Text:     "// TODO 0",
+			Comment:  "first todo",
+			Filename: files[0],
+		}, {
+			Text:     "// TODO 1",
+			Comment:  "second todo",
+			Filename: files[1],
+		}, {
+			Text:     "// TODO 2",
+			Comment:  "todo in third file",
+			Filename: files[2],
+		}}...)
+}
+
+func (s *baseSuite) TestReviewConcurrentlySharesTMPDIR(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+	b.ReviewConcurrently(4)
+
+	var mu sync.Mutex
+	dirs := map[string]bool{}
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		dir, err := r.(tenet.BaseReview).TMPDIR()
+		c.Check(err, jc.ErrorIsNil)
+		mu.Lock()
+		dirs[dir] = true
+		mu.Unlock()
+		r.SmellDoneWithFile()
+		return nil
+	})
+
+	var files []string
+	for i := 0; i < 8; i++ {
+		files = append(files, s.TmpFile(c, "package mock\n"))
+	}
+	s.CheckFiles(c, files)
+
+	// Every file is smelt in the same dir, which is removed with the review.
+	c.Assert(dirs, gc.HasLen, 1)
+	for dir := range dirs {
+		_, err := os.Stat(dir)
+		c.Assert(os.IsNotExist(err), jc.IsTrue)
+	}
+}
+
+func (s *baseSuite) TestIssuePositions(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("line")
+	b.RegisterIssue("range")
+
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if bytes.Contains(line, []byte("var x")) {
+			r.RaiseLineIssue("line", n, n)
+			r.RaiseRangeIssue("range", n, 5, n, 6)
+		}
+		return nil
+	})
+
+	fName := s.TmpFile(c, "package mock\n\nvar x = 1\n")
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	issues := tt.ReadAllIssues(c, br)
+	c.Assert(issues, gc.HasLen, 2)
+
+	// A line issue spans the whole line.
+	line := issues[0].Position
+	c.Assert(line.Start, gc.Equals, token.Position{Filename: fName, Offset: 14, Line: 3, Column: 1})
+	c.Assert(line.End, gc.Equals, token.Position{Filename: fName, Offset: 23, Line: 3, Column: 10})
+
+	// A range issue spans the given columns.
+	rng := issues[1].Position
+	c.Assert(rng.Start, gc.Equals, token.Position{Filename: fName, Offset: 18, Line: 3, Column: 5})
+	c.Assert(rng.End, gc.Equals, token.Position{Filename: fName, Offset: 19, Line: 3, Column: 6})
+}
+
+func (s *baseSuite) TestFix(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("bad_name")
+	b.RegisterIssue("no_space")
+
+	tenet.OnNode(b, func(r tenet.Review, ident *ast.Ident) error {
+		if ident.Name == "bad_name" {
+			r.RaiseNodeIssue("bad_name", ident, tenet.Fix(ast.NewIdent("goodName")))
+		}
+		return nil
+	})
+
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if bytes.HasPrefix(line, []byte("//x")) {
+			// The comment is on line 3, starting at byte 14.
+			r.RaiseLineIssue("no_space", n, n, tenet.Fix(tenet.TextEdit{Start: 16, End: 16, NewText: " "}))
+		}
+		return nil
+	})
+
+	fName := s.TmpFile(c, "package mock\n\n//x\nvar bad_name = 1\n")
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	issues := tt.ReadAllIssues(c, br)
+	c.Assert(issues, gc.HasLen, 2)
+
+	// The patch paths are relative.
+	fName = strings.TrimPrefix(fName, "/")
+
+	c.Assert(issues[0].Err, jc.ErrorIsNil)
+	c.Assert(issues[0].Patch, gc.Equals, fmt.Sprintf(`--- a/%s
++++ b/%s
+@@ -1,4 +1,4 @@
+ package mock
+ 
+ //x
+-var bad_name = 1
++var goodName = 1
+`, fName, fName))
+
+	c.Assert(issues[1].Err, jc.ErrorIsNil)
+	c.Assert(issues[1].Patch, gc.Equals, fmt.Sprintf(`--- a/%s
++++ b/%s
+@@ -1,4 +1,4 @@
+ package mock
+ 
+-//x
++// x
+ var bad_name = 1
+`, fName, fName))
+}
+
+func (s *baseSuite) TestFixIndentsLinesOutsideLiterals(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("int_x")
+
+	// Replace x := 1 with a func literal returning a raw string.
+	tenet.OnNode(b, func(r tenet.Review, assign *ast.AssignStmt) error {
+		if assign.Tok != token.DEFINE {
+			return nil
+		}
+		r.RaiseNodeIssue("int_x", assign, tenet.Fix(&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("x")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.FuncLit{
+					Type: &ast.FuncType{
+						Params:  &ast.FieldList{},
+						Results: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent("string")}}},
+					},
+					Body: &ast.BlockStmt{List: []ast.Stmt{
+						&ast.ReturnStmt{Results: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: "`a\nb`"}}},
+					}},
+				},
+			}},
+		}))
+		return nil
+	})
+
+	fName := s.TmpFile(c, "package mock\n\nfunc f() {\n\tx := 1\n\t_ = x\n}\n")
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	issues := tt.ReadAllIssues(c, br)
+	c.Assert(issues, gc.HasLen, 1)
+	c.Assert(issues[0].Err, jc.ErrorIsNil)
+
+	// The second line of the raw string is not indented.
+	fName = strings.TrimPrefix(fName, "/")
+	c.Assert(issues[0].Patch, gc.Equals, fmt.Sprintf(`--- a/%s
++++ b/%s
+@@ -1,6 +1,9 @@
+ package mock
+ 
+ func f() {
+-	x := 1
++	x := func() string {
++		return `+"`a\n+b`"+`
++	}()
+ 	_ = x
+ }
+`, fName, fName))
+}
+
+func (s *baseSuite) TestFixBadType(c *gc.C) {
+	c.Assert(func() { tenet.Fix("x") }, gc.PanicMatches, `tenet.Fix expects an ast.Node, TextEdit or \[\]TextEdit, got string`)
+}
+
+func (s *baseSuite) TestNamedFixes(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("bad_name")
+
+	tenet.OnNode(b, func(r tenet.Review, ident *ast.Ident) error {
+		if ident.Name == "bad_name" {
+			r.RaiseNodeIssue("bad_name", ident,
+				tenet.NamedFix("camel case", ast.NewIdent("badName")),
+				tenet.NamedFix("rename", ast.NewIdent("goodName")),
+			)
+		}
+		return nil
+	})
+
+	fName := s.TmpFile(c, "package mock\n\nvar bad_name = 1\n")
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	issues := tt.ReadAllIssues(c, br)
+	c.Assert(issues, gc.HasLen, 1)
+	c.Assert(issues[0].Err, jc.ErrorIsNil)
+
+	// Only the first fix is rendered as the patch.
+	c.Assert(issues[0].Patch, jc.Contains, "+var badName = 1\n")
+
+	start := &api.Position{Filename: fName, Offset: 18, Line: 3, Column: 5}
+	end := &api.Position{Filename: fName, Offset: 26, Line: 3, Column: 13}
+	c.Assert(tenet.APIIssue(issues[0]).Fixes, jc.DeepEquals, []*api.Fix{{
+		Name: "camel case",
+		Edits: []*api.TextEdit{{
+			Start:   start,
+			End:     end,
+			NewText: "badName",
+		}},
+	}, {
+		Name: "rename",
+		Edits: []*api.TextEdit{{
+			Start:   start,
+			End:     end,
+			NewText: "goodName",
+		}},
+	}})
+}
+
+const suppressedSRC = `package mock
+
+// TODO one //lingo:ignore baseTestTenet/todo it's fine
+//lingo:ignore baseTestTenet/*
+// TODO two
+// TODO three
+// lingo:ignore otherTenet/todo
+// TODO four
+`
+
+func (s *baseSuite) registerTODOIssue(b *tenet.Base) {
+	b.RegisterIssue("todo",
+		tenet.AddComment("first todo", tenet.FirstComment),
+		tenet.AddComment("second todo", tenet.SecondComment),
+	)
+
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if bytes.Contains(line, []byte("TODO")) {
+			r.RaiseLineIssue("todo", n, n)
+		}
+		return nil
+	})
+}
+
+func (s *baseSuite) TestSuppressedIssuesAreDropped(c *gc.C) {
+	s.registerTODOIssue(s.Tenet.(*tenet.Base))
+
+	// Suppressed issues don't use up a comment context.
+	s.CheckSRC(c, suppressedSRC, []tt.ExpectedIssue{
+		{
+			Text:    "// TODO three",
+			Comment: "first todo",
+		}, {
+			Text:    "// TODO four",
+			Comment: "second todo",
+		}}...)
+}
+
+func (s *baseSuite) TestReportSuppressed(c *gc.C) {
+	s.registerTODOIssue(s.Tenet.(*tenet.Base))
+	s.SetCfgOption(c, "report_suppressed", "true")
+
+	fName := s.TmpFile(c, suppressedSRC)
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	var got []string
+	for _, issue := range tt.ReadAllIssues(c, br) {
+		got = append(got, fmt.Sprintf("%s: %s, %v", issue.LineText, issue.Comment, issue.Suppressed))
+	}
+	c.Assert(got, jc.DeepEquals, []string{
+		"// TODO one //lingo:ignore baseTestTenet/todo it's fine: first todo, true",
+		"// TODO two: first todo, true",
+		"// TODO three: first todo, false",
+		"// TODO four: second todo, false",
+	})
+}
+
+func (s *baseSuite) TestFileIgnore(c *gc.C) {
+	s.registerTODOIssue(s.Tenet.(*tenet.Base))
+
+	ignored := s.TmpFile(c, "// lingo:file-ignore baseTestTenet generated\npackage mock\n\n// TODO\n")
+	reviewed := s.TmpFile(c, "package mock\n\n// TODO\n")
+	s.CheckFiles(c, []string{ignored, reviewed}, tt.ExpectedIssue{
+		Filename: reviewed,
+		Text:     "// TODO",
+		Comment:  "first todo",
+	})
+}
+
+func (s *baseSuite) TestBareIgnoreSuppressesEveryIssue(c *gc.C) {
+	s.registerTODOIssue(s.Tenet.(*tenet.Base))
+
+	ignored := s.TmpFile(c, "//lingo:file-ignore\npackage mock\n\n// TODO\n")
+	reviewed := s.TmpFile(c, "package mock\n\n// TODO one /* lingo:ignore */\n\n// TODO two\n")
+	s.CheckFiles(c, []string{ignored, reviewed}, tt.ExpectedIssue{
+		Filename: reviewed,
+		Text:     "// TODO two",
+		Comment:  "first todo",
+	})
+}
+
+func (s *baseSuite) TestIgnoreInStringIsNotADirective(c *gc.C) {
+	s.registerTODOIssue(s.Tenet.(*tenet.Base))
+
+	s.CheckSRC(c, "package mock\n\nvar s = `\n// lingo:ignore\n` // TODO\n", tt.ExpectedIssue{
+		Text:    "` // TODO",
+		Comment: "first todo",
+	})
+}
+
+func (s *baseSuite) TestBaseline(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+	s.registerTODOIssue(b)
+
+	// The review's tmp dir is removed when it closes.
+	dir := c.MkDir()
+	path := filepath.Join(dir, "baseline.json")
+	old := filepath.Join(dir, "old.go")
+	c.Assert(ioutil.WriteFile(old, []byte("package mock\n\n// TODO old\n// TODO old\n"), 0644), jc.ErrorIsNil)
+
+	s.SetCfgOption(c, "write_baseline", path)
+	s.CheckFiles(c, []string{old}, []tt.ExpectedIssue{
+		{
+			Filename: old,
+			Text:     "// TODO old",
+			Comment:  "first todo",
+		}, {
+			Filename: old,
+			Text:     "// TODO old",
+			Comment:  "second todo",
+		}}...)
+
+	// Lines moved, reindented and a third copy added: only the third copy is
+	// new.
+	c.Assert(ioutil.WriteFile(old, []byte("package mock\n\nvar x = 1\n\n//  TODO old\n// TODO old\n// TODO old\n"), 0644), jc.ErrorIsNil)
+
+	// Comment contexts are used up, so start again with a fresh tenet.
+	b = &tenet.Base{}
+	b.SetInfo(tenet.Info{Name: "baseTestTenet"})
+	s.registerTODOIssue(b)
+	s.Tenet = b
+	s.Review = b.NewReview()
+	s.SetCfgOption(c, "baseline", path)
+	s.CheckFiles(c, []string{old}, tt.ExpectedIssue{
+		Filename: old,
+		Text:     "// TODO old",
+		Comment:  "first todo",
+	})
+
+	// Once the option is cleared, the baseline is no longer used.
+	s.Review = b.NewReview()
+	s.SetCfgOption(c, "baseline", "")
+	s.CheckFiles(c, []string{old}, []tt.ExpectedIssue{
+		{
+			Filename: old,
+			Text:     "//  TODO old",
+			Comment:  "first todo",
+		}, {
+			Filename: old,
+			Text:     "// TODO old",
+			Comment:  "second todo",
+		}}...)
+}
+
+func (s *baseSuite) TestFingerprint(c *gc.C) {
+	dir := c.MkDir()
+	fName := filepath.Join(dir, "fingerprint.go")
+
+	fingerprints := func(src string) []string {
+		b := &tenet.Base{}
+		b.SetInfo(tenet.Info{Name: "baseTestTenet"})
+		b.RegisterIssue("todo")
+		b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+			if bytes.Contains(line, []byte("TODO")) {
+				r.RaiseLineIssue("todo", n, n)
+			}
+			return nil
+		})
+
+		c.Assert(ioutil.WriteFile(fName, []byte(src), 0644), jc.ErrorIsNil)
+		br := b.NewReview()
+		br.StartReview()
+		go func() {
+			defer br.EndReview()
+			br.SendFile(&api.File{Name: fName})
+		}()
+
+		var fps []string
+		for _, issue := range tt.ReadAllIssues(c, br) {
+			fps = append(fps, issue.Fingerprint)
+		}
+		return fps
+	}
+
+	before := fingerprints("package mock\n\nfunc f() {\n\t// TODO\n\t// TODO\n}\n\nfunc g() {\n\t// TODO\n}\n")
+	c.Assert(before, gc.HasLen, 3)
+
+	// The same line twice in one declaration, or in another declaration, is
+	// a different issue.
+	c.Assert(before[0], gc.Not(gc.Equals), before[1])
+	c.Assert(before[0], gc.Not(gc.Equals), before[2])
+
+	// Moving and reindenting the code does not change the fingerprints.
+	after := fingerprints("package mock\n\nvar x = 1\n\nfunc g() {\n  // TODO\n}\n\nfunc f() {\n\n  // TODO\n  // TODO\n}\n")
+	c.Assert(after, jc.DeepEquals, []string{before[2], before[0], before[1]})
+}
+
+func (s *baseSuite) TestSeverity(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("default")
+	b.RegisterIssue("error", tenet.Severity(tenet.SeverityError))
+	b.RegisterIssue("hint", tenet.Severity(tenet.SeverityHint))
+
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if n == 1 {
+			r.RaiseLineIssue("default", n, n)
+			r.RaiseLineIssue("error", n, n)
+			r.RaiseLineIssue("hint", n, n)
+		}
+		return nil
+	})
+
+	err := b.MixinConfigOptions([]*api.Option{{Name: "severity.hint", Value: "fatal"}})
+	c.Assert(err, gc.ErrorMatches, `invalid value for option "severity.hint": unknown severity "fatal", expected one of "error", "warning", "info" or "hint"\n(?s:.*)`)
+	s.SetCfgOption(c, "severity.hint", "info")
+
+	fName := s.TmpFile(c, "package mock\n")
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	var got []tenet.SeverityLevel
+	for _, issue := range tt.ReadAllIssues(c, br) {
+		got = append(got, issue.Severity)
+	}
+	c.Assert(got, jc.DeepEquals, []tenet.SeverityLevel{tenet.SeverityWarning, tenet.SeverityError, tenet.SeverityInfo})
+}
+
+func (s *baseSuite) TestSeverityUnknownLevel(c *gc.C) {
+	c.Assert(func() { tenet.Severity("fatal") }, gc.PanicMatches, `unknown severity "fatal".*`)
+}
+
+func (s *baseSuite) TestTypedOptions(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	i := b.RegisterIntOption("int", 1, "an int")
+	f := b.RegisterFloatOption("float", 0.5, "a float")
+	t := b.RegisterBoolOption("bool", false, "a bool")
+	l := b.RegisterStringListOption("list", []string{"a"}, "a list")
+	re := b.RegisterRegexOption("regex", "^a$", "a regex")
+
+	c.Assert(*i, gc.Equals, 1)
+	c.Assert(*f, gc.Equals, 0.5)
+	c.Assert(*t, gc.Equals, false)
+	c.Assert(*l, jc.DeepEquals, []string{"a"})
+	c.Assert(re.Load().MatchString("a"), jc.IsTrue)
+
+	// A smell may still be using the old regex, so it is not changed.
+	old := re.Load()
+
+	c.Assert(b.MixinConfigOptions([]*api.Option{
+		{Name: "int", Value: "42"},
+		{Name: "float", Value: "2.5"},
+		{Name: "bool", Value: "true"},
+		{Name: "list", Value: "x, y,,z"},
+		{Name: "regex", Value: "^b$"},
+	}), jc.ErrorIsNil)
+
+	c.Assert(*i, gc.Equals, 42)
+	c.Assert(*f, gc.Equals, 2.5)
+	c.Assert(*t, gc.Equals, true)
+	c.Assert(*l, jc.DeepEquals, []string{"x", "y", "z"})
+	c.Assert(re.Load().MatchString("a"), jc.IsFalse)
+	c.Assert(re.Load().MatchString("b"), jc.IsTrue)
+	c.Assert(old.MatchString("a"), jc.IsTrue)
+
+	for _, bad := range []struct {
+		name, value, err string
+	}{
+		{"int", "forty", `invalid value for option "int": expected an integer, got "forty"`},
+		{"float", "half", `invalid value for option "float": expected a number, got "half"`},
+		{"bool", "yes please", `invalid value for option "bool": expected true or false, got "yes please"`},
+		{"regex", "[", `invalid value for option "regex": expected a regular expression, got "\[": .*`},
+	} {
+		err := b.MixinConfigOptions([]*api.Option{{Name: bad.name, Value: bad.value}})
+		c.Check(err, gc.ErrorMatches, bad.err+`\n(?s:.*)`)
+	}
+
+	// Rejected values leave the option as it was.
+	c.Assert(*i, gc.Equals, 42)
+	c.Assert(re.Load().MatchString("b"), jc.IsTrue)
+}
+
+func (s *baseSuite) TestConfigErrorListsEveryRejectedOption(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+	b.RegisterIssue("issue")
+	v := b.RegisterOption("key", "default", "usage string")
+	i := b.RegisterIntOption("int", 1, "an int")
+
+	err := b.MixinConfigOptions([]*api.Option{
+		{Name: "kye", Value: "value"},
+		{Name: "key", Value: "value"},
+		{Name: "int", Value: "one"},
+	})
+	c.Assert(err, gc.ErrorMatches, `tenet has no option "kye"
+invalid value for option "int": expected an integer, got "one"
+valid options are:
+  key: usage string
+  int: an int
+  report_suppressed: .*
+  baseline: .*
+  write_baseline: .*
+  locale: .*
+  new_code_only: .*
+  new_code_radius: .*
+  include_files: .*
+  exclude_files: .*
+  severity.syntax_error: .*
+  severity.issue: .*`)
+
+	// Valid options are still set.
+	c.Assert(*v, gc.Equals, "value")
+	c.Assert(*i, gc.Equals, 1)
+}
+
+func (s *baseSuite) TestOptionsBeforeSetInfo(c *gc.C) {
+	b := &tenet.Base{}
+	err := b.MixinConfigOptions([]*api.Option{{Name: "key", Value: "value"}})
+	c.Assert(err, gc.ErrorMatches, `cannot set options "key" before the tenet's info is set: the tenet must call SetInfo first`)
+}
+
+func (s *baseSuite) TestParameterisedCommentContexts(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("line",
+		tenet.AddComment("second", tenet.NthComment(2)),
+		tenet.AddComment("every third", tenet.EveryNthComment(3)),
+		tenet.AddComment("after ten", tenet.AfterNComments(10)),
+	)
+
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		r.RaiseLineIssue("line", n, n, tenet.CommentVar("n", n))
+		return nil
+	})
+
+	fName := s.TmpFile(c, "package mock\n"+strings.Repeat("//\n", 11))
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	var got []string
+	for _, issue := range tt.ReadAllIssues(c, br) {
+		got = append(got, fmt.Sprintf("%d: %s", issue.Position.Start.Line, issue.Comment))
+	}
+	c.Assert(got, jc.DeepEquals, []string{
+		"2: second",
+		"3: every third",
+		"6: every third",
+		"9: every third",
+		"11: after ten",
+		"12: every third",
+		"13: after ten", // the empty line at the end of the file
+	})
+
+	// Every third and after ten have no end.
+	s.assertCxtFull(c, false)
+}
+
+func (s *baseSuite) TestFirstNCommentsInNthFile(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("line",
+		tenet.AddComment("first two in the second file", tenet.FirstNComments(2), tenet.InNthFile(2)),
+	)
+
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		r.RaiseLineIssue("line", n, n)
+		return nil
+	})
+
+	src := "package mock\n// 2nd line\n// 3rd line"
+	files := []string{
+		s.TmpFile(c, src),
+		s.TmpFile(c, src),
+		s.TmpFile(c, src),
+	}
+	s.CheckFiles(c, files, []tt.ExpectedIssue{
+		{
+			Text:     "package mock",
+			Comment:  "first two in the second file",
+			Filename: files[1],
+		}, {
+			Text:     "// 2nd line",
+			Comment:  "first two in the second file",
+			Filename: files[1],
+		}}...)
+
+	// The comment has been used for the last time.
+	s.assertCxtFull(c, true)
+}
+
+func (s *baseSuite) TestParameterisedContextBadN(c *gc.C) {
+	c.Assert(func() { tenet.NthComment(0) }, gc.PanicMatches, `NthComment expects n to be at least 1, got 0`)
+}
+
+func (s *baseSuite) TestLocalizedComments(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("greeting",
+		tenet.AddComment("hello"),
+		tenet.AddLocalizedComment("fr", "bonjour", tenet.FirstComment),
+		tenet.AddLocalizedComment("pt", "olá"),
+		tenet.AddLocalizedComment("pt_BR", "oi"),
+	)
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if n < 3 {
+			r.RaiseLineIssue("greeting", n, n)
+		}
+		return nil
+	})
+
+	c.Assert(tenet.APIInfo(b.Info()).Locales, jc.DeepEquals, []string{"fr", "pt", "pt-br"})
+
+	fName := s.TmpFile(c, "package mock\n\n// two issues\n")
+	for locale, expected := range map[string][]string{
+		"":      {"hello", "hello"},
+		"de":    {"hello", "hello"},
+		"fr-CA": {"bonjour", "hello"},
+		"pt-BR": {"oi", "oi"},
+		"pt-PT": {"olá", "olá"},
+	} {
+		s.SetCfgOption(c, "locale", locale)
+		br := b.NewReview()
+		br.StartReview()
+		go func() {
+			defer br.EndReview()
+			br.SendFile(&api.File{Name: fName})
+		}()
+
+		var got []string
+		for _, issue := range tt.ReadAllIssues(c, br) {
+			got = append(got, issue.Comment)
+		}
+		c.Check(got, jc.DeepEquals, expected, gc.Commentf("locale %q", locale))
+	}
+}
+
+func (s *baseSuite) TestCommentInOtherLanguageIsNotUsed(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("greeting",
+		tenet.AddComment("hello", tenet.SecondComment),
+		tenet.AddLocalizedComment("fr", "bonjour", tenet.FirstComment),
+	)
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		r.RaiseLineIssue("greeting", n, n)
+		return nil
+	})
+
+	// The first issue only has a comment in French, so it is not raised.
+	s.SetCfgOption(c, "locale", "de")
+	s.CheckSRC(c, "package mock\n// two issues", tt.ExpectedIssue{
+		Text:    "// two issues",
+		Comment: "hello",
+	})
+}
+
+func (s *baseSuite) TestConfiguredComments(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("issue",
+		tenet.AddComment("first built in", tenet.FirstComment),
+		tenet.AddComment("built in {{.n}}"),
+		tenet.AddLocalizedComment("fr", "en français"),
+	)
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if n < 4 {
+			r.RaiseLineIssue("issue", n, n, tenet.CommentVar("n", n))
+		}
+		return nil
+	})
+
+	for _, t := range []struct {
+		name, value, err string
+	}{{
+		name:  "comment.issue.first",
+		value: "{{.n",
+		err:   `invalid value for option "comment.issue.first": bad comment template: template: comment template:1: unclosed action`,
+	}, {
+		name: "comment.nosuchissue.first",
+		err:  `invalid value for option "comment.nosuchissue.first": tenet has no issue "nosuchissue"`,
+	}, {
+		name: "comment.issue.sixth",
+		err:  `invalid value for option "comment.issue.sixth": unknown comment context "sixth", expected one of "default", "fifth", "first", "fourth", "second", "third"`,
+	}, {
+		name: "comment.issue",
+		err:  `invalid value for option "comment.issue": expected an option named comment.<issue>.<context>`,
+	}} {
+		err := b.MixinConfigOptions([]*api.Option{{Name: t.name, Value: t.value}})
+		c.Check(err, gc.ErrorMatches, regexp.QuoteMeta(t.err)+`\n(?s:.*)`)
+	}
+
+	s.SetCfgOption(c, "locale", "fr")
+	s.SetCfgOption(c, "comment.issue.second", "configured second")
+	s.SetCfgOption(c, "comment.issue.default", "configured default")
+	s.SetCfgOption(c, "comment.issue.default", "configured {{.n}}")
+
+	fName := s.TmpFile(c, "package mock\n\n// three issues\n")
+	review := func() []string {
+		br := b.NewReview()
+		br.StartReview()
+		go func() {
+			defer br.EndReview()
+			br.SendFile(&api.File{Name: fName})
+		}()
+		var got []string
+		for _, issue := range tt.ReadAllIssues(c, br) {
+			got = append(got, issue.Comment)
+		}
+		return got
+	}
+	c.Assert(review(), jc.DeepEquals, []string{"configured 1", "configured second", "configured 3"})
+
+	// An empty template removes the configured comment.
+	s.SetCfgOption(c, "comment.issue.default", "")
+	s.SetCfgOption(c, "comment.issue.second", "")
+	s.SetCfgOption(c, "locale", "")
+	c.Assert(review(), jc.DeepEquals, []string{"first built in", "built in 2", "built in 3"})
+}
+
+func (s *baseSuite) TestTemplateFuncs(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterTemplateFunc("shout", strings.ToUpper)
+	b.RegisterIssue("issue", tenet.AddComment(
+		`{{quote .args | join ", "}} {{plural .args "isn't" "aren't"}} used, {{plural 1 "arg" "args"}} in {{code .node}}: {{truncate 8 "a long description"}} {{shout "done"}}`))
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if n == 1 {
+			r.RaiseLineIssue("issue", n, n,
+				tenet.CommentVar("args", []string{"a", "b"}),
+				tenet.CommentVar("node", &ast.CallExpr{Fun: ast.NewIdent("f"), Args: []ast.Expr{ast.NewIdent("a")}}),
+			)
+		}
+		return nil
+	})
+
+	s.CheckSRC(c, "package mock\n", tt.ExpectedIssue{
+		Text:    "package mock",
+		Comment: "\"a\", \"b\" aren't used, arg in `f(a)`: a lon... DONE",
+	})
+}
+
+func (s *baseSuite) TestConfiguredCommentWithTemplateFuncs(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+	b.RegisterIssue("issue")
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if n == 1 {
+			r.RaiseLineIssue("issue", n, n,
+				tenet.CommentVar("args", []string{"a", "b"}),
+				tenet.CommentVar("name", "a long description"),
+				tenet.CommentVar("node", ast.NewIdent("f")),
+			)
+		}
+		return nil
+	})
+
+	// The variables are only set when the issue is raised, so the template
+	// is not run when the option is set.
+	s.SetCfgOption(c, "comment.issue.default",
+		`{{quote .args | join ", "}} {{plural .args "isn't" "aren't"}} used in {{code .node}}: {{truncate 10 .name}}`)
+	s.CheckSRC(c, "package mock\n", tt.ExpectedIssue{
+		Text:    "package mock",
+		Comment: "\"a\", \"b\" aren't used in `f`: a long ...",
+	})
+}
+
+func (s *baseSuite) TestConfiguredCommentUnknownTemplateFunc(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+	b.RegisterIssue("issue")
+
+	err := b.MixinConfigOptions([]*api.Option{{Name: "comment.issue.default", Value: `{{shout "x"}}`}})
+	c.Assert(err, gc.ErrorMatches, `invalid value for option "comment.issue.default": bad comment template: .*function "shout" not defined\n(?s:.*)`)
+
+	b.RegisterTemplateFunc("shout", strings.ToUpper)
+	s.SetCfgOption(c, "comment.issue.default", `{{shout "x"}}`)
+}
+
+func (s *baseSuite) TestAddedLines(c *gc.C) {
+	lines := func(s string) [][]byte {
+		return bytes.Split([]byte(s), []byte("\n"))
+	}
+	// lcs is the length of the longest common subsequence of a and b.
+	lcs := func(a, b [][]byte) int {
+		prev := make([]int, len(b)+1)
+		for i := range a {
+			cur := make([]int, len(b)+1)
+			for j := range b {
+				switch {
+				case bytes.Equal(a[i], b[j]):
+					cur[j+1] = prev[j] + 1
+				case prev[j+1] > cur[j]:
+					cur[j+1] = prev[j+1]
+				default:
+					cur[j+1] = cur[j]
+				}
+			}
+			prev = cur
+		}
+		return prev[len(b)]
+	}
+
+	c.Assert(tenet.AddedLines(lines("a\nb\nc"), lines("a\nx\nb\nc\ny")), jc.DeepEquals, map[int]bool{2: true, 5: true})
+
+	// The lines not added are common to both sides, and there are as many of
+	// them as there can be.
+	rnd := rand.New(rand.NewSource(1))
+	random := func() [][]byte {
+		l := make([][]byte, rnd.Intn(30))
+		for i := range l {
+			l[i] = []byte{byte('a' + rnd.Intn(4))}
+		}
+		return l
+	}
+	for i := 0; i < 500; i++ {
+		base, head := random(), random()
+		added := tenet.AddedLines(base, head)
+		var kept [][]byte
+		for j, l := range head {
+			if !added[j+1] {
+				kept = append(kept, l)
+			}
+		}
+		c.Assert(lcs(base, kept), gc.Equals, len(kept), gc.Commentf("base %q, head %q", base, head))
+		c.Assert(len(kept), gc.Equals, lcs(base, head), gc.Commentf("base %q, head %q", base, head))
+	}
+
+	// Large files are diffed without a table of every pair of lines.
+	var base, head [][]byte
+	for i := 0; i < 50000; i++ {
+		l := []byte(fmt.Sprint(i % 1000))
+		base = append(base, l)
+		head = append(head, l)
+		if i%100 == 0 {
+			head = append(head, []byte(fmt.Sprint(i%1000+500)))
+		}
+	}
+	c.Assert(tenet.AddedLines(base, head), gc.HasLen, 500)
+}
+
+func (s *baseSuite) TestNewCode(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("issue")
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if len(line) > 0 {
+			r.RaiseLineIssue("issue", n, n)
+		}
+		return nil
+	})
+
+	base := "package mock\n\nvar a = 1\n\nvar b = 2\n\nvar c = 3\n"
+	head := "package mock\n\nvar a = 1\nvar new = 0\n\nvar b = 2\n\nvar c = 3\n"
+	diff := `--- a/mock.go
++++ b/mock.go
+@@ -1,4 +1,5 @@
+ package mock
+ 
+ var a = 1
++var new = 0
+ 
+`
+	fName := s.TmpFile(c, head)
+
+	review := func(file *api.File) (lines []int, newCode []bool) {
+		br := b.NewReview()
+		br.StartReview()
+		go func() {
+			defer br.EndReview()
+			br.SendFile(file)
+		}()
+		for _, issue := range tt.ReadAllIssues(c, br) {
+			lines = append(lines, issue.Position.Start.Line)
+			newCode = append(newCode, issue.NewCode)
+		}
+		return lines, newCode
+	}
+
+	// Without a diff, no code is new.
+	lines, newCode := review(&api.File{Name: fName})
+	c.Assert(lines, jc.DeepEquals, []int{1, 3, 4, 6, 8})
+	c.Assert(newCode, jc.DeepEquals, []bool{false, false, false, false, false})
+
+	// The added line is found from either a diff, one with the leading space
+	// of blank context lines stripped, or the base content.
+	for _, file := range []*api.File{
+		{Name: fName, Diff: diff},
+		{Name: fName, Diff: strings.Replace(diff, "\n \n", "\n\n", -1)},
+		{Name: fName, BaseContent: base},
+	} {
+		lines, newCode = review(file)
+		c.Assert(lines, jc.DeepEquals, []int{1, 3, 4, 6, 8})
+		c.Assert(newCode, jc.DeepEquals, []bool{false, false, true, false, false})
+
+		s.SetCfgOption(c, "new_code_only", "true")
+		lines, newCode = review(file)
+		c.Assert(lines, jc.DeepEquals, []int{4})
+		c.Assert(newCode, jc.DeepEquals, []bool{true})
+
+		s.SetCfgOption(c, "new_code_radius", "2")
+		lines, _ = review(file)
+		c.Assert(lines, jc.DeepEquals, []int{3, 4, 6})
+
+		s.SetCfgOption(c, "new_code_only", "false")
+		s.SetCfgOption(c, "new_code_radius", "0")
+	}
+
+	// A diff which only renames the file adds no lines.
+	rename := "diff --git a/old.go b/mock.go\nsimilarity index 100%\nrename from old.go\nrename to mock.go\n"
+	s.SetCfgOption(c, "new_code_only", "true")
+	lines, _ = review(&api.File{Name: fName, Diff: rename})
+	c.Assert(lines, gc.HasLen, 0)
+	s.SetCfgOption(c, "new_code_only", "false")
+
+	err := b.MixinConfigOptions([]*api.Option{{Name: "new_code_radius", Value: "-1"}})
+	c.Assert(err, gc.ErrorMatches, `invalid value for option "new_code_radius": expected a radius of 0 or more, got -1\n(?s:.*)`)
+}
+
+func (s *baseSuite) TestTextFiles(c *gc.C) {
+	dir := c.MkDir()
+	goFile := filepath.Join(dir, "main.go")
+	yamlFile := filepath.Join(dir, "config.yaml")
+	dockerFile := filepath.Join(dir, "Dockerfile")
+	c.Assert(ioutil.WriteFile(goFile, []byte("package main\n\t\n"), 0644), jc.ErrorIsNil)
+	c.Assert(ioutil.WriteFile(yamlFile, []byte("a: 1\nb: 2\t\n"), 0644), jc.ErrorIsNil)
+	c.Assert(ioutil.WriteFile(dockerFile, []byte("FROM scratch\t\n"), 0644), jc.ErrorIsNil)
+
+	for _, t := range []struct {
+		info     tenet.Info
+		expected []string
+	}{{
+		info:     tenet.Info{Name: "go", Language: "golang"},
+		expected: []string{"main.go:2"},
+	}, {
+		info:     tenet.Info{Name: "any", Language: "text"},
+		expected: []string{"main.go:2", "config.yaml:2", "Dockerfile:1"},
+	}, {
+		info:     tenet.Info{Name: "yaml", Extensions: []string{".yaml", ""}},
+		expected: []string{"config.yaml:2", "Dockerfile:1"},
+	}} {
+		b := &tenet.Base{}
+		b.SetInfo(t.info)
+		b.RegisterIssue("trailing_whitespace")
+		b.SmellNode(func(r tenet.Review, n *ast.File) error {
+			c.Check(r.File().Filename(), gc.Equals, goFile)
+			return nil
+		})
+		b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+			if bytes.HasSuffix(line, []byte("\t")) {
+				r.RaiseLineIssue("trailing_whitespace", n, n)
+			}
+			return nil
+		})
+		c.Assert(tenet.APIInfo(b.Info()).Extensions, jc.DeepEquals, t.info.Extensions)
+
+		br := b.NewReview()
+		br.StartReview()
+		go func() {
+			defer br.EndReview()
+			for _, name := range []string{goFile, yamlFile, dockerFile} {
+				br.SendFile(&api.File{Name: name})
+			}
+		}()
+
+		var got []string
+		for _, issue := range tt.ReadAllIssues(c, br) {
+			got = append(got, fmt.Sprintf("%s:%d", filepath.Base(issue.Filename()), issue.Position.Start.Line))
+		}
+		c.Check(got, jc.DeepEquals, t.expected, gc.Commentf("tenet %q", t.info.Name))
+	}
+}
+
+func (s *baseSuite) TestLineOnlyGoTenetReviewsEveryFile(c *gc.C) {
+	dir := c.MkDir()
+	goFile := filepath.Join(dir, "main.go")
+	readme := filepath.Join(dir, "README.md")
+	c.Assert(ioutil.WriteFile(goFile, []byte("package main\n\t\n"), 0644), jc.ErrorIsNil)
+	c.Assert(ioutil.WriteFile(readme, []byte("# main\t\n"), 0644), jc.ErrorIsNil)
+
+	for _, t := range []struct {
+		info     tenet.Info
+		expected []string
+	}{{
+		info:     tenet.Info{Name: "go", Language: "golang"},
+		expected: []string{"main.go:2", "README.md:1"},
+	}, {
+		info:     tenet.Info{Name: "go_only", Language: "golang", Extensions: []string{".go"}},
+		expected: []string{"main.go:2"},
+	}} {
+		b := &tenet.Base{}
+		b.SetInfo(t.info)
+		b.RegisterIssue("trailing_whitespace")
+		b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+			if bytes.HasSuffix(line, []byte("\t")) {
+				r.RaiseLineIssue("trailing_whitespace", n, n)
+			}
+			return nil
+		})
+
+		br := b.NewReview()
+		br.StartReview()
+		go func() {
+			defer br.EndReview()
+			for _, name := range []string{goFile, readme} {
+				br.SendFile(&api.File{Name: name})
+			}
+		}()
+
+		var got []string
+		for _, issue := range tt.ReadAllIssues(c, br) {
+			got = append(got, fmt.Sprintf("%s:%d", filepath.Base(issue.Filename()), issue.Position.Start.Line))
+		}
+		c.Check(got, jc.DeepEquals, t.expected, gc.Commentf("tenet %q", t.info.Name))
+	}
+}
+
+func (s *baseSuite) TestSyntaxErrors(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("todo")
+	var funcs []string
+	b.SmellNode(func(r tenet.Review, fn *ast.FuncDecl) error {
+		funcs = append(funcs, fn.Name.Name)
+		return nil
+	})
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if bytes.Contains(line, []byte("TODO")) {
+			r.RaiseLineIssue("todo", n, n)
+		}
+		return nil
+	})
+
+	fName := s.TmpFile(c, `package mock
+
+func a() {
+	println(1 2)
+}
+
+// TODO finish b
+func b() {}
+
+func c() {
+	if {}
+}
+`)
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	type found struct {
+		name, comment string
+		line, col     int
+		severity      tenet.SeverityLevel
+	}
+	var got []found
+	for _, issue := range tt.ReadAllIssues(c, br) {
+		got = append(got, found{issue.Name, issue.Comment, issue.Position.Start.Line, issue.Position.Start.Column, issue.Severity})
+	}
+	c.Assert(got, jc.DeepEquals, []found{
+		{"syntax_error", "syntax error: missing ',' in argument list", 4, 12, tenet.SeverityError},
+		{"syntax_error", "syntax error: missing condition in if statement", 11, 5, tenet.SeverityError},
+		{"todo", "Issue Found", 7, 1, tenet.SeverityWarning},
+	})
+
+	// Node smells run on the partial AST.
+	c.Assert(funcs, jc.DeepEquals, []string{"a", "b", "c"})
+}
+
+func (s *baseSuite) TestSyntaxErrorsWithoutInfo(c *gc.C) {
+	b := &tenet.Base{}
+	b.RegisterIssue("todo")
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		return nil
+	})
+
+	fName := s.TmpFile(c, "package a\nfunc {")
+	br := b.NewReview()
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	issues := tt.ReadAllIssues(c, br)
+	c.Assert(issues, gc.HasLen, 1)
+	c.Assert(issues[0].Name, gc.Equals, "syntax_error")
+	c.Assert(issues[0].Severity, gc.Equals, tenet.SeverityError)
+}
+
+func (s *baseSuite) TestNoPackageClauseIsReviewedAsText(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("line")
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if n == 1 {
+			r.RaiseLineIssue("line", n, n)
+		}
+		return nil
+	})
+
+	fName := s.TmpFile(c, "not go\n")
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: fName})
+	}()
+
+	var got []string
+	for _, issue := range tt.ReadAllIssues(c, br) {
+		got = append(got, fmt.Sprintf("%d:%d %s", issue.Position.Start.Line, issue.Position.Start.Column, issue.Comment))
+	}
+	c.Assert(got, jc.DeepEquals, []string{
+		"1:1 syntax error: expected 'package', found not",
+		"1:1 Issue Found",
+	})
+}
+
+func (s *baseSuite) TestFileContent(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.Init()
+	b.RegisterIssue("todo", tenet.AddComment("{{.line}}"))
+	b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+		if bytes.Contains(line, []byte("TODO")) {
+			r.RaiseLineIssue("todo", n, n, tenet.CommentVar("line", string(line)))
+		}
+		return nil
+	})
+
+	// The files are not on disk, only sent with their content.
+	dir := c.MkDir()
+	src := "package mock\n\n// TODO naïve\n"
+	utf16 := []byte{0xff, 0xfe}
+	for _, r := range src {
+		utf16 = append(utf16, byte(r), 0)
+	}
+	latin1 := []byte(strings.Replace(src, "ï", "\xef", 1))
+
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: filepath.Join(dir, "utf8.go"), Content: []byte(src)})
+		br.SendFile(&api.File{Name: filepath.Join(dir, "utf16.go"), Content: utf16, Encoding: "utf-16"})
+		br.SendFile(&api.File{Name: filepath.Join(dir, "latin1.go"), Content: latin1, Encoding: "latin1"})
+		br.SendFile(&api.File{Name: filepath.Join(dir, "bad.go"), Content: []byte(src), Encoding: "ebcdic"})
+	}()
+
+	var got []string
+	for _, issue := range tt.ReadAllIssues(c, br) {
+		got = append(got, filepath.Base(issue.Filename())+": "+issue.Comment)
+	}
+	c.Assert(got, jc.DeepEquals, []string{
+		"utf8.go: // TODO naïve",
+		"utf16.go: // TODO naïve",
+		"latin1.go: // TODO naïve",
+	})
+
+	err := <-b.Errors()
+	c.Assert(err, gc.ErrorMatches, `could not find file: .*: could not decode ".*bad.go": unknown encoding "ebcdic"`)
+}
+
+func (s *baseSuite) TestFileContentReplacesSiblingParsedFromDisk(c *gc.C) {
+	b := s.Tenet.(*tenet.Base)
+
+	b.RegisterIssue("func", tenet.AddComment("{{.name}} {{.defined}}"))
+	b.SmellNode(func(r tenet.Review, fnc *ast.FuncDecl) error {
+		// Type checking a.go parses b.go from disk.
+		info := r.Package().TypesInfo()
+		r.RaiseNodeIssue("func", fnc,
+			tenet.CommentVar("name", fnc.Name.Name),
+			tenet.CommentVar("defined", info.Defs[fnc.Name] != nil),
+		)
+		return nil
+	})
+
+	dir := c.MkDir()
+	a := filepath.Join(dir, "a.go")
+	bFile := filepath.Join(dir, "b.go")
+	c.Assert(ioutil.WriteFile(a, []byte("package mock\n\nfunc FromA() {}\n"), 0644), jc.ErrorIsNil)
+	c.Assert(ioutil.WriteFile(bFile, []byte("package mock\n\nfunc FromDisk() {}\n"), 0644), jc.ErrorIsNil)
+
+	br := s.Review.(tenet.BaseReview)
+	br.StartReview()
+	go func() {
+		defer br.EndReview()
+		br.SendFile(&api.File{Name: a})
+		br.SendFile(&api.File{Name: bFile, Content: []byte("package mock\n\nfunc FromContent() {}\n")})
+	}()
+
+	// b.go is smelt as sent, and type checked again with its content.
+	var got []string
+	for _, issue := range tt.ReadAllIssues(c, br) {
+		got = append(got, filepath.Base(issue.Filename())+": "+issue.Comment+", "+issue.LineText)
+	}
+	c.Assert(got, jc.DeepEquals, []string{
+		"a.go: FromA true, func FromA() {}",
+		"b.go: FromContent true, func FromContent() {}",
+	})
+}
+
+func (s *baseSuite) TestGeneratedFilesAreSkipped(c *gc.C) {
+	dir := c.MkDir()
+	files := map[string]string{
+		"handwritten.go": "// Package mock is not generated.\npackage mock\n",
+		"stringer.go":    "// Code generated by stringer; DO NOT EDIT.\n\npackage mock\n",
+		"api.pb.go":      "// Code generated by protoc-gen-go.\n// source: api.proto\n// DO NOT EDIT!\n\npackage mock\n",
+		"late.go":        "package mock\n\n// Code generated by hand. DO NOT EDIT.\n",
+	}
+	var names []string
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		c.Assert(ioutil.WriteFile(path, []byte(src), 0644), jc.ErrorIsNil)
+		names = append(names, path)
+	}
+	sort.Strings(names)
+
+	for _, include := range []bool{false, true} {
+		b := &tenet.Base{}
+		b.SetInfo(tenet.Info{Name: "generated", IncludeGenerated: include})
+		b.RegisterIssue("file")
+		b.SmellLine(func(r tenet.Review, n int, line []byte) error {
+			if n == 1 {
+				r.RaiseLineIssue("file", n, n, tenet.CommentVar("generated", r.File().IsGenerated()))
+			}
+			return nil
+		})
+
+		br := b.NewReview()
+		br.StartReview()
+		go func() {
+			defer br.EndReview()
+			for _, name := range names {
+				br.SendFile(&api.File{Name: name})
+			}
+		}()
+
+		var got []string
+		for _, issue := range tt.ReadAllIssues(c, br) {
+			got = append(got, fmt.Sprintf("%s %v", filepath.Base(issue.Filename()), issue.CommVars["generated"]))
+		}
+		expected := []string{"handwritten.go false", "late.go false"}
+		if include {
+			expected = []string{"api.pb.go true", "handwritten.go false", "late.go false", "stringer.go true"}
+		}
+		c.Check(got, jc.DeepEquals, expected, gc.Commentf("IncludeGenerated: %v", include))
+	}
+}
+
+func (s *baseSuite) TestIncludeExcludeFiles(c *gc.C) {
+	dir := c.MkDir()
+	var names []string
+	for _, name := range []string{"a.go", "a_test.go", "vendor/b/b.go", "vendor/b/b_test.go", "vendor/github.com/x/y/c_test.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), jc.ErrorIsNil)
+		c.Assert(ioutil.WriteFile(path, []byte("package mock\n"), 0644), jc.ErrorIsNil)
+		names = append(names, path)
+	}
+
+	b := &tenet.Base{}
+	b.SetInfo(tenet.Info{
+		Name:         "filtered",
+		IncludeFiles: []string{"*_test.go"},
+		ExcludeFiles: []string{"vendor/*"},
+	})
+	b.RegisterIssue("file")
+	b.SmellNode(func(r tenet.Review, f *ast.File) error {
+		r.RaiseNodeIssue("file", f.Name)
+		return nil
+	})
+
+	review := func() []string {
+		br := b.NewReview()
+		br.StartReview()
+		go func() {
+			defer br.EndReview()
+			for _, name := range names {
+				br.SendFile(&api.File{Name: name})
+			}
+		}()
+		var got []string
+		for _, issue := range tt.ReadAllIssues(c, br) {
+			rel, err := filepath.Rel(dir, issue.Filename())
+			c.Assert(err, jc.ErrorIsNil)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		return got
+	}
+	c.Assert(review(), jc.DeepEquals, []string{"a_test.go"})
+
+	// Users can override the tenet's globs.
+	s.Tenet = b
+	s.SetCfgOption(c, "include_files", "")
+	c.Assert(review(), jc.DeepEquals, []string{"a.go", "a_test.go"})
+	s.SetCfgOption(c, "exclude_files", "*_test.go")
+	c.Assert(review(), jc.DeepEquals, []string{"a.go", "vendor/b/b.go"})
+
+	// A glob matching a dir matches everything below it, and ** matches any
+	// number of dirs.
+	s.SetCfgOption(c, "exclude_files", "vendor")
+	c.Assert(review(), jc.DeepEquals, []string{"a.go", "a_test.go"})
+	s.SetCfgOption(c, "exclude_files", "")
+	s.SetCfgOption(c, "include_files", "vendor/**/*_test.go")
+	c.Assert(review(), jc.DeepEquals, []string{"vendor/b/b_test.go", "vendor/github.com/x/y/c_test.go"})
+
+	err := b.MixinConfigOptions([]*api.Option{{Name: "exclude_files", Value: "[a-"}})
+	c.Assert(err, gc.ErrorMatches, `invalid value for option "exclude_files": bad glob "\[a-"\n(?s:.*)`)
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/juju/errors"
+)
+
+// baseline holds the fingerprints of known issues, as set by setFingerprint.
+// An issue found in the baseline is not raised. A baseline is written from one
+// review and read by later ones, so that adopting a tenet only raises new
+// issues.
+type baseline struct {
+	Issues []*baselineEntry `json:"issues"`
+
+	// index holds the fingerprints of Issues.
+	index map[string]bool
+}
+
+// baselineEntry is an issue in the baseline. The fields other than
+// Fingerprint are only there for the reader of the file.
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Issue       string `json:"issue"`
+	Filename    string `json:"filename"`
+	Line        string `json:"line"`
+}
+
+// loadBaseline reads the baseline file at path.
+func loadBaseline(path string) (*baseline, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	bl := &baseline{}
+	if err := json.Unmarshal(data, bl); err != nil {
+		return nil, errors.Annotatef(err, "could not read baseline %q", path)
+	}
+	bl.index = map[string]bool{}
+	for _, e := range bl.Issues {
+		bl.index[e.Fingerprint] = true
+	}
+	return bl, nil
+}
+
+// has returns true if an issue with the fingerprint is in the baseline.
+func (bl *baseline) has(fp string) bool {
+	return bl != nil && bl.index[fp]
+}
+
+// add records the issue and returns its fingerprint.
+func (bl *baseline) add(issue *Issue) string {
+	bl.Issues = append(bl.Issues, &baselineEntry{
+		Fingerprint: issue.Fingerprint,
+		Issue:       issue.Name,
+		Filename:    filepath.ToSlash(issue.Filename()),
+		Line:        normalizeSpace(issue.LineText),
+	})
+	return issue.Fingerprint
+}
+
+// startBaseline loads the baseline set by the "baseline" option, if any. The
+// baseline is only used by this review.
+func (r *review) startBaseline() {
+	b := r.baseTenet()
+	if b.baselinePath == nil || *b.baselinePath == "" {
+		return
+	}
+	bl, err := loadBaseline(*b.baselinePath)
+	if err != nil {
+		b.SendError(errors.Trace(err))
+		return
+	}
+	r.known = bl
+}
+
+// endBaseline writes the baseline set by the "write_baseline" option, if
+// any.
+func (r *review) endBaseline() {
+	b := r.baseTenet()
+	if !b.writesBaseline() {
+		return
+	}
+	if err := r.WriteBaseline(*b.writeBaselinePath); err != nil {
+		b.SendError(errors.Trace(err))
+	}
+}
+
+// inBaseline returns true if the issue with the fingerprint is in the
+// review's baseline.
+func (r *review) inBaseline(fp string) bool {
+	return r.known.has(fp)
+}
+
+// WriteBaseline writes every issue found so far in the review, other than
+// those suppressed, to a baseline file at path.
+func (r *review) WriteBaseline(path string) error {
+	issues := append([]*baselineEntry(nil), r.found.Issues...)
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Filename != issues[j].Filename {
+			return issues[i].Filename < issues[j].Filename
+		}
+		return issues[i].Issue < issues[j].Issue
+	})
+
+	data, err := json.MarshalIndent(&baseline{Issues: issues}, "", "  ")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(ioutil.WriteFile(path, append(data, '\n'), 0644))
+}
@@ -3,6 +3,12 @@
 
 package tenet
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // CommentContext is a bit operator which dictates when the comment should be
 // used. e.g. first, second, last time an issue is encountered.
 type CommentContext int
@@ -39,19 +45,189 @@ var commContext = map[int]CommentContext{
 	5: FifthComment,
 }
 
+// commentContextNames maps the names used in comment options to the contexts
+// they set.
+var commentContextNames = map[string]CommentContext{
+	"default": DefaultComment,
+	"first":   FirstComment,
+	"second":  SecondComment,
+	"third":   ThirdComment,
+	"fourth":  FourthComment,
+	"fifth":   FifthComment,
+}
+
+// commentContextNamesList returns the quoted names of commentContextNames.
+func commentContextNamesList() string {
+	var names []string
+	for name := range commentContextNames {
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Parameterised contexts are not bit flags. They hold their kind and n above
+// the bits of the flag contexts, and cannot be or'ed with other contexts.
+const (
+	contextKindShift  = 16
+	contextParamShift = 20
+	contextKindMask   = 0xf
+)
+
+type contextKind int
+
+const (
+	flagContext contextKind = iota
+	nthComment
+	everyNthComment
+	afterNComments
+	firstNComments
+	inNthFile
+)
+
+func paramContext(kind contextKind, name string, n int) CommentContext {
+	if n < 1 {
+		// Yes panic, this is a developer error.
+		panic(fmt.Sprintf("%s expects n to be at least 1, got %d", name, n))
+	}
+	return CommentContext(n<<contextParamShift | int(kind)<<contextKindShift)
+}
+
+// NthComment is the context of the nth time an issue is found.
+func NthComment(n int) CommentContext {
+	return paramContext(nthComment, "NthComment", n)
+}
+
+// EveryNthComment is the context of every nth time an issue is found, i.e.
+// the nth, 2nth, 3nth and so on.
+func EveryNthComment(n int) CommentContext {
+	return paramContext(everyNthComment, "EveryNthComment", n)
+}
+
+// AfterNComments is the context of every time an issue is found after the
+// first n times.
+func AfterNComments(n int) CommentContext {
+	return paramContext(afterNComments, "AfterNComments", n)
+}
+
+// FirstNComments is the context of the first n times an issue is found. Once
+// they have all been found, the comment goes quiet.
+func FirstNComments(n int) CommentContext {
+	return paramContext(firstNComments, "FirstNComments", n)
+}
+
+// InNthFile scopes a comment to the nth file an issue is found in.
+func InNthFile(n int) CommentContext {
+	return paramContext(inNthFile, "InNthFile", n)
+}
+
+func (ctx CommentContext) kind() contextKind {
+	return contextKind(int(ctx) >> contextKindShift & contextKindMask)
+}
+
+func (ctx CommentContext) param() int {
+	return int(ctx) >> contextParamShift
+}
+
+// split returns each context in ctx. Flag contexts may be or'ed together,
+// parameterised contexts are returned as is.
+func (ctx CommentContext) split() []CommentContext {
+	if ctx.kind() != flagContext {
+		return []CommentContext{ctx}
+	}
+	var ctxs []CommentContext
+	for flag := DefaultComment; flag <= InOverall; flag <<= 1 {
+		if ctx&flag != 0 {
+			ctxs = append(ctxs, flag)
+		}
+	}
+	return ctxs
+}
+
+// matchesCount returns true if the comment context ctx applies to the nth
+// time an issue was found.
+func (ctx CommentContext) matchesCount(n int) bool {
+	switch ctx.kind() {
+	case nthComment:
+		return n == ctx.param()
+	case everyNthComment:
+		return n%ctx.param() == 0
+	case afterNComments:
+		return n > ctx.param()
+	case firstNComments:
+		return n <= ctx.param()
+	}
+	if ctx == DefaultComment {
+		return true
+	}
+	return ctx == commContext[n]
+}
+
+// lastCount returns the last count matched by the comment context ctx, or
+// false if it matches counts without end.
+func (ctx CommentContext) lastCount() (int, bool) {
+	switch ctx.kind() {
+	case nthComment, firstNComments:
+		return ctx.param(), true
+	case everyNthComment, afterNComments:
+		return 0, false
+	}
+	for n, commCtx := range commContext {
+		if ctx == commCtx {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// matchesFile returns true if the file context ctx applies to the nth file
+// an issue was found in.
+func (ctx CommentContext) matchesFile(n int) bool {
+	if ctx.kind() == inNthFile {
+		return n == ctx.param()
+	}
+	if ctx == InEveryFile {
+		return true
+	}
+	return ctx == fileContext[n]
+}
+
+// contextPair is a comment context scoped to a file context.
+type contextPair struct {
+	file    CommentContext
+	comment CommentContext
+}
+
+// bounded returns true if the pair can only match a known number of issues,
+// after which it is done.
+func (p contextPair) bounded() bool {
+	if _, ok := p.comment.lastCount(); !ok {
+		return false
+	}
+	return p.file != InEveryFile
+}
+
 type comment struct {
 
 	// the comment template.
 	Template string
 
+	// the language of the comment, or "" for the default comment.
+	lang string
+
+	// configured is true if the comment was set by the user, in which case
+	// it is used over the tenet's own comments in the same context.
+	configured bool
+
 	// the context in which this comment should be used.
 	commentContexts []CommentContext
 
 	// the file context to which the commentContext is scoped.
 	fileContexts []CommentContext
 
-	// a map of each context the comment was found in.
-	matches map[CommentContext]bool
+	// the bounded contexts in which the comment has been used for the last
+	// time.
+	done map[contextPair]bool
 }
 
 func (c *comment) addCommentCtx(ctx CommentContext) {
@@ -62,57 +238,61 @@ func (c *comment) addFileCtx(ctx CommentContext) {
 	c.fileContexts = append(c.fileContexts, ctx)
 }
 
-// add a context in which this comment was matched.
-func (c *comment) addMatch(ctx CommentContext) {
-	c.matches[ctx] = true
-}
-
+// allContextsMatched returns true if the comment can not be used again.
+// Contexts without an end, such as DefaultComment, EveryNthComment or
+// InEveryFile, are never matched.
 func (c *comment) allContextsMatched() bool {
-	for _, ctx := range c.allContexts() {
-		var matched bool
-		for matchedCtx := range c.matches {
-
-			// If we matched on a default, don't count it.
-			if ctx&(DefaultComment|InEveryFile) != 0 {
-				continue
-			}
-
-			if ctx&matchedCtx == ctx {
-				matched = true
-				break
-			}
-		}
-		if !matched {
+	for _, pair := range c.allContexts() {
+		if !pair.bounded() || !c.done[pair] {
 			return false
 		}
 	}
 	return true
 }
 
-// returns a slice of each commentContext scoped to a file context.
-func (c *comment) allContexts() []CommentContext {
-	var ctxs []CommentContext
+// returns each commentContext scoped to a file context.
+func (c *comment) allContexts() []contextPair {
+	var pairs []contextPair
 	for _, fCtx := range c.fileContexts {
 		for _, commCtx := range c.commentContexts {
-			ctxs = append(ctxs, fCtx|commCtx)
+			pairs = append(pairs, contextPair{fCtx, commCtx})
 		}
 	}
-	return ctxs
+	return pairs
 }
 
-func (c *comment) matchesContext(ctx CommentContext) bool {
-	for _, commCtx := range c.allContexts() {
-		if commCtx&ctx == commCtx {
-			return true
+// match returns true if the comment applies to an issue found for the
+// inFile'th time in the fileOrder'th file it was found in, and the overall'th
+// time overall. The bounded contexts used for the last time are marked as
+// done.
+func (c *comment) match(fileOrder, inFile, overall int) bool {
+	var found bool
+	for _, pair := range c.allContexts() {
+		count := inFile
+		if pair.file == InOverall {
+			count = overall
+		} else if !pair.file.matchesFile(fileOrder) {
+			continue
+		}
+		if !pair.comment.matchesCount(count) {
+			continue
+		}
+		found = true
+
+		if last, ok := pair.comment.lastCount(); ok && count == last {
+			c.done[pair] = true
 		}
 	}
-	return false
+	return found
 }
 
 func isFileContext(ctx CommentContext) bool {
+	if ctx.kind() == inNthFile {
+		return true
+	}
 
-	// Every file is a special case not mapped in fileContext
-	if ctx&InEveryFile != 0 {
+	// Every file and overall are special cases not mapped in fileContext
+	if ctx&(InEveryFile|InOverall) != 0 {
 		return true
 	}
 
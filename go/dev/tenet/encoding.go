@@ -0,0 +1,75 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
+	"github.com/lingo-reviews/tenets/go/dev/api"
+)
+
+// fileContent returns the content sent with the file, as UTF-8, or nil if
+// the file was sent without its content and must be read from disk.
+func fileContent(file *api.File) ([]byte, error) {
+	if len(file.Content) == 0 {
+		return nil, nil
+	}
+	content, err := decodeContent(file.Content, file.Encoding)
+	return content, errors.Annotatef(err, "could not decode %q", file.Name)
+}
+
+// decodeContent returns content, in the named encoding, as UTF-8. The
+// encodings are utf-8, the default, utf-16, utf-16le, utf-16be and latin1.
+// utf-16 without a byte order mark is read as big endian.
+func decodeContent(content []byte, encoding string) ([]byte, error) {
+	switch strings.ToLower(strings.Replace(encoding, "_", "-", -1)) {
+	case "", "utf-8", "utf8":
+		return content, nil
+	case "utf-16", "utf16":
+		switch {
+		case bytes.HasPrefix(content, []byte{0xff, 0xfe}):
+			return decodeUTF16(content[2:], binary.LittleEndian)
+		case bytes.HasPrefix(content, []byte{0xfe, 0xff}):
+			return decodeUTF16(content[2:], binary.BigEndian)
+		}
+		return decodeUTF16(content, binary.BigEndian)
+	case "utf-16le", "utf16le":
+		return decodeUTF16(bytes.TrimPrefix(content, []byte{0xff, 0xfe}), binary.LittleEndian)
+	case "utf-16be", "utf16be":
+		return decodeUTF16(bytes.TrimPrefix(content, []byte{0xfe, 0xff}), binary.BigEndian)
+	case "latin1", "latin-1", "iso-8859-1":
+		buf := make([]byte, 0, len(content))
+		for _, b := range content {
+			buf = appendRune(buf, rune(b))
+		}
+		return buf, nil
+	}
+	return nil, errors.Errorf("unknown encoding %q", encoding)
+}
+
+func decodeUTF16(content []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(content)%2 != 0 {
+		return nil, errors.New("utf-16 content has an odd number of bytes")
+	}
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		units[i] = order.Uint16(content[2*i:])
+	}
+	buf := make([]byte, 0, len(content))
+	for _, r := range utf16.Decode(units) {
+		buf = appendRune(buf, r)
+	}
+	return buf, nil
+}
+
+func appendRune(buf []byte, r rune) []byte {
+	var b [utf8.UTFMax]byte
+	n := utf8.EncodeRune(b[:], r)
+	return append(buf, b[:n]...)
+}
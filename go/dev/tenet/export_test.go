@@ -3,3 +3,7 @@ package tenet
 func AreAllContextsMatched(r BaseReview) bool {
 	return r.areAllContextsMatched()
 }
+
+func AddedLines(base, head [][]byte) map[int]bool {
+	return addedLines(base, head)
+}
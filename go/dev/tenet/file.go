@@ -7,8 +7,11 @@ import (
 	"bytes"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
 	"go/token"
 	"io/ioutil"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/juju/errors"
@@ -27,6 +30,23 @@ type gofile struct {
 	lines     [][]byte
 	diffLines []int64
 	filename  string
+
+	// lineOffsets holds the byte offset of the start of each line.
+	lineOffsets []int
+
+	// suppressed holds the lingo:ignore directives in the file.
+	suppressed *suppressions
+
+	// occurrences counts the issues raised in the file, by fingerprint key.
+	occurrences map[string]int
+
+	// added holds the line numbers added by the change under review, or is
+	// nil if the file was sent without a diff.
+	added map[int]bool
+	// syntaxErrors holds the errors found parsing the file.
+	syntaxErrors scanner.ErrorList
+	// generated is true if the file's header marks it as generated code.
+	generated bool
 }
 
 func (f *gofile) AST() *ast.File {
@@ -45,18 +65,73 @@ func (f *gofile) diff() []int64 {
 	return f.diffLines
 }
 
+func (f *gofile) syntaxErrs() scanner.ErrorList {
+	return f.syntaxErrors
+}
+
+func (f *gofile) newLines() map[int]bool {
+	return f.added
+}
+
+func (f *gofile) setNewLines(added map[int]bool) {
+	f.added = added
+}
+
+// linePosition returns the position of the start of line.
 func (f *gofile) linePosition(line int) token.Position {
+	return f.position(line, 1)
+}
+
+// position returns the position of the column, a byte count starting at 1,
+// on line. The offset is clamped to the lines of the file.
+func (f *gofile) position(line, column int) token.Position {
+	var offset int
+	if n := len(f.lineOffsets); n > 0 {
+		switch {
+		case line < 1:
+			offset = 0
+		case line > n:
+			offset = f.lineOffsets[n-1] + len(f.lines[n-1])
+		default:
+			offset = f.lineOffsets[line-1] + column - 1
+			if end := f.lineOffsets[line-1] + len(f.lines[line-1]); offset > end {
+				offset = end
+			}
+		}
+	}
+
 	return token.Position{
 		Filename: f.Filename(),
-		Offset:   f.Fset().Base(), // offset, starting at 0
-		Line:     line,            // line number, starting at 1
-		// TODO(matt, waigani) implement. We'll have to update the AddIssue methods. Needs to be done before stable v1.
-		// Column   int    // column number, starting at 1 (character count)
+		Offset:   offset, // offset, starting at 0
+		Line:     line,   // line number, starting at 1
+		Column:   column, // column number, starting at 1 (byte count)
+	}
+}
+
+// offsetPosition returns the position of the byte offset, starting at 0.
+func (f *gofile) offsetPosition(offset int) token.Position {
+	// The last line starting at or before offset.
+	line := sort.Search(len(f.lineOffsets), func(i int) bool {
+		return f.lineOffsets[i] > offset
+	})
+	if line == 0 {
+		return f.position(1, 1)
 	}
+	return f.position(line, offset-f.lineOffsets[line-1]+1)
 }
 
+// newIssueRange spans from the start of the start line to the end of the end
+// line.
 func (f *gofile) newIssueRange(start, end int) *issueRange {
-	return &issueRange{f.linePosition(start), f.linePosition(end)}
+	return &issueRange{f.linePosition(start), f.position(end, len(f.Line(end))+1)}
+}
+
+func (f *gofile) newIssueRangeFromOffsets(start, end int) *issueRange {
+	return &issueRange{f.offsetPosition(start), f.offsetPosition(end)}
+}
+
+func (f *gofile) newIssueRangeFromColumns(startLine, startCol, endLine, endCol int) *issueRange {
+	return &issueRange{f.position(startLine, startCol), f.position(endLine, endCol)}
 }
 
 func (f *gofile) newIssueRangeFromNode(n ast.Node) *issueRange {
@@ -88,10 +163,33 @@ func (f *gofile) Lines() [][]byte {
 
 func (f *gofile) setLines(lines [][]byte) {
 	f.lines = lines
+	f.lineOffsets = make([]int, len(lines))
+	var offset int
+	for i, line := range lines {
+		f.lineOffsets[i] = offset
+		// +1 for the newline
+		offset += len(line) + 1
+	}
+	f.generated = isGenerated(lines)
+}
+
+func (f *gofile) suppressions() *suppressions {
+	return f.suppressed
+}
+
+// occurrence returns the number of times key has been passed to occurrence
+// before.
+func (f *gofile) occurrence(key string) int {
+	if f.occurrences == nil {
+		f.occurrences = map[string]int{}
+	}
+	n := f.occurrences[key]
+	f.occurrences[key]++
+	return n
 }
 
 func (f *gofile) IsMain() bool {
-	if f.AST().Name.Name == "main" {
+	if f.AST() != nil && f.AST().Name.Name == "main" {
 		return true
 	}
 	return false
@@ -99,32 +197,91 @@ func (f *gofile) IsMain() bool {
 
 func (f *gofile) IsTest() bool { return strings.HasSuffix(f.Filename(), "_test.go") }
 
-func buildFile(path, src string, fset *token.FileSet, diffLines []int64) (File, error) {
-	var srcBytes []byte
-	if src == "" {
+func (f *gofile) IsGenerated() bool {
+	return f.generated
+}
+
+// buildFile builds a File from src, or from the file at path if src is nil.
+// If path has already been parsed, while type checking a sibling file, its
+// cached AST is reused. Otherwise, the parsed AST is added to asts. A file
+// that is not Go source is built as a text file, without an AST.
+func buildFile(path string, src []byte, fset *token.FileSet, asts map[string]*ast.File, diffLines []int64) (File, error) {
+	srcBytes := src
+	if srcBytes == nil {
 		var err error
 		// TODO(matt) TECHDEBT use "go/scanner".Scanner instead of loading all bytes to memory.
 		srcBytes, err = ioutil.ReadFile(path)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-	} else {
-		srcBytes = []byte(src)
 	}
 
-	f, err := parser.ParseFile(fset, path, srcBytes, parser.ParseComments)
-	if err != nil {
-		return nil, errors.Trace(err)
+	if !isGoFile(path) {
+		return buildTextFile(path, srcBytes, fset, diffLines), nil
+	}
+
+	// A file with syntax errors is still reviewed, with the partial AST the
+	// parser returns, and its syntax errors are raised as issues. Content
+	// sent with the file is always parsed, as the AST may have been parsed
+	// from disk while type checking another file of the package.
+	var syntaxErrors scanner.ErrorList
+	f, ok := asts[path]
+	if !ok || src != nil {
+		var err error
+		f, err = parser.ParseFile(fset, path, srcBytes, parser.ParseComments|parser.AllErrors)
+		if err != nil {
+			list, ok := err.(scanner.ErrorList)
+			if !ok {
+				return nil, errors.Trace(err)
+			}
+			// Only the first error on each line is kept. The rest tend
+			// to follow from it.
+			list.RemoveMultiples()
+			syntaxErrors = list
+		}
+
+		// Without a package clause, the parser gives up on the file. It is
+		// reviewed as text.
+		if f.Package == token.NoPos {
+			f = nil
+			delete(asts, path)
+		} else {
+			asts[path] = f
+		}
 	}
 
 	// type info
+	file := &gofile{
+		filename:     path,
+		ast:          f,
+		fset:         fset,
+		diffLines:    diffLines,
+		syntaxErrors: syntaxErrors,
+	}
+
+	file.setLines(bytes.Split(srcBytes, []byte("\n")))
+	if f != nil {
+		file.suppressed = parseCommentSuppressions(fset, f)
+	} else {
+		file.suppressed = parseSuppressions(file.lines)
+	}
+	return file, nil
+}
+
+// buildTextFile builds a File of src which is smelt line by line. It has no
+// AST, so node smells and package smells are not run on it.
+func buildTextFile(path string, src []byte, fset *token.FileSet, diffLines []int64) File {
 	file := &gofile{
 		filename:  path,
-		ast:       f,
 		fset:      fset,
 		diffLines: diffLines,
 	}
+	file.setLines(bytes.Split(src, []byte("\n")))
+	file.suppressed = parseSuppressions(file.lines)
+	return file
+}
 
-	file.setLines(bytes.Split(srcBytes, []byte("\n")))
-	return file, nil
+// isGoFile returns true if the file at path is Go source.
+func isGoFile(path string) bool {
+	return filepath.Ext(path) == ".go"
 }
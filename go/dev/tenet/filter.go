@@ -0,0 +1,84 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// matchesFilters returns true if the file at filename matches one of the
+// IncludeFiles globs, if there are any, and none of the ExcludeFiles globs.
+func (i *Info) matchesFilters(filename string) bool {
+	if len(i.IncludeFiles) > 0 && !matchesAnyGlob(i.IncludeFiles, filename) {
+		return false
+	}
+	return !matchesAnyGlob(i.ExcludeFiles, filename)
+}
+
+func matchesAnyGlob(globs []string, filename string) bool {
+	for _, glob := range globs {
+		if matchesGlob(glob, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesGlob returns true if glob matches consecutive elements of the path
+// of filename, starting at any element, e.g. "*_test.go" matches its base
+// name and "vendor/*" matches "src/vendor/a.go". Each element of glob is
+// matched as by path.Match, except for "**", which matches any number of
+// elements. A glob matching a dir matches every file below it.
+func matchesGlob(glob, filename string) bool {
+	globs := strings.Split(glob, "/")
+	elems := strings.Split(filepath.ToSlash(filename), "/")
+	for i := range elems {
+		if matchesElems(globs, elems[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesElems returns true if globs match the leading elements of elems.
+func matchesElems(globs, elems []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	if globs[0] == "**" {
+		for i := 0; i <= len(elems); i++ {
+			if matchesElems(globs[1:], elems[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elems) == 0 {
+		return false
+	}
+	ok, _ := path.Match(globs[0], elems[0])
+	return ok && matchesElems(globs[1:], elems[1:])
+}
+
+// parseGlobs parses a comma separated list of globs into v, rejecting any
+// that are malformed.
+func parseGlobs(v *[]string) func(string) error {
+	return func(s string) error {
+		var globs []string
+		if err := parseStringList(&globs)(s); err != nil {
+			return err
+		}
+		for _, glob := range globs {
+			if _, err := path.Match(glob, ""); err != nil {
+				return errors.Errorf("bad glob %q", glob)
+			}
+		}
+		*v = globs
+		return nil
+	}
+}
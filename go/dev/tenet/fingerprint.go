@@ -0,0 +1,85 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// setFingerprint sets a fingerprint identifying the issue across revisions of
+// the file. It is built from the tenet and issue names, the filename, the top
+// level declaration the issue is in and the text of the issue's line(s),
+// ignoring whitespace, but not from line numbers. Identical issues in the same
+// declaration are told apart by the order they were raised in. It must be
+// called after the issue's source has been set.
+func (issue *Issue) setFingerprint(tenetName string) *Issue {
+	key := strings.Join([]string{
+		tenetName,
+		issue.Name,
+		filepath.ToSlash(issue.Filename()),
+		enclosingDecl(issue.file, issue.Position.Start.Offset),
+		normalizeSpace(issue.LineText),
+	}, "\x00")
+
+	var n int
+	if f, ok := issue.file.(BaseFile); ok {
+		n = f.occurrence(key)
+	}
+
+	sum := sha1.Sum([]byte(key + "\x00" + strconv.Itoa(n)))
+	issue.Fingerprint = fmt.Sprintf("%x", sum)
+	return issue
+}
+
+// normalizeSpace trims text and replaces each run of whitespace in it with a
+// single space, so that reindenting a line does not change it.
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// enclosingDecl describes the top level declaration of f at offset, e.g.
+// "func (*T).Method" or "type T". It is empty if offset is not in a
+// declaration.
+func enclosingDecl(f File, offset int) string {
+	if f.AST() == nil {
+		return ""
+	}
+	for _, decl := range f.AST().Decls {
+		start := f.Fset().Position(decl.Pos()).Offset
+		end := f.Fset().Position(decl.End()).Offset
+		if offset < start || offset >= end {
+			continue
+		}
+
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				return fmt.Sprintf("func (%s).%s", types.ExprString(decl.Recv.List[0].Type), decl.Name.Name)
+			}
+			return "func " + decl.Name.Name
+		case *ast.GenDecl:
+			var names []string
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, spec.Name.Name)
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						names = append(names, name.Name)
+					}
+				case *ast.ImportSpec:
+					names = append(names, spec.Path.Value)
+				}
+			}
+			return decl.Tok.String() + " " + strings.Join(names, ",")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,294 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// TextEdit replaces the bytes of the reviewed file in the range [Start, End)
+// with NewText. Start and End are byte offsets, starting at 0.
+type TextEdit struct {
+	Start   int
+	End     int
+	NewText string
+}
+
+// IssueFix is a named set of edits resolving an issue, resolved against the
+// reviewed file.
+type IssueFix struct {
+	Name  string
+	Edits []*FixEdit
+}
+
+// FixEdit replaces the source spanned by Range with NewText.
+type FixEdit struct {
+	Range   *issueRange
+	NewText string
+}
+
+type namedFix struct {
+	name string
+	fix  interface{}
+}
+
+// Fix returns a RaiseIssueOption which attaches a fix to the issue. The fix is
+// rendered as a unified diff against the reviewed file and set on
+// Issue.Patch. fix is one of:
+//
+// - an ast.Node, which replaces the source spanned by the issue.
+// - a TextEdit or []TextEdit, which are applied to the source of the file.
+//
+// e.g.
+// r.RaiseNodeIssue("bad_name", ident, tenet.Fix(ast.NewIdent("goodName")))
+func Fix(fix interface{}) RaiseIssueOption {
+	return NamedFix("", fix)
+}
+
+// NamedFix is like Fix, but names the fix so a user can choose between
+// alternatives. An issue can be raised with several fixes. Only the first is
+// rendered into Issue.Patch; all are set on Issue.Fixes.
+//
+// e.g.
+//
+//	r.RaiseNodeIssue("bad_name", ident,
+//		tenet.NamedFix("use camel case", ast.NewIdent("badName")),
+//		tenet.NamedFix("rename", ast.NewIdent("goodName")),
+//	)
+func NamedFix(name string, fix interface{}) RaiseIssueOption {
+	switch fix.(type) {
+	case ast.Node, TextEdit, []TextEdit:
+	default:
+		// Yes panic, this is a developer error.
+		panic(fmt.Sprintf("tenet.Fix expects an ast.Node, TextEdit or []TextEdit, got %T", fix))
+	}
+	return func(issue *Issue) {
+		issue.fixes = append(issue.fixes, namedFix{name, fix})
+	}
+}
+
+// setFixes resolves the issue's fixes, if any, into Issue.Fixes and renders
+// the first into Issue.Patch. It must be called after the issue's source has
+// been set.
+func (issue *Issue) setFixes() *Issue {
+	src := bytes.Join(issue.file.Lines(), []byte("\n"))
+	for i, f := range issue.fixes {
+		edits, err := issue.fixEdits(f.fix)
+		if err == nil && i == 0 {
+			issue.Patch, err = unifiedDiff(issue.Filename(), src, edits)
+		}
+		if err != nil {
+			issue.Err = errors.Annotatef(err, "could not build fix for issue %q", issue.Name)
+			return issue
+		}
+
+		fix := &IssueFix{Name: f.name}
+		for _, e := range edits {
+			fix.Edits = append(fix.Edits, &FixEdit{
+				Range:   issue.file.(BaseFile).newIssueRangeFromOffsets(e.Start, e.End),
+				NewText: e.NewText,
+			})
+		}
+		issue.Fixes = append(issue.Fixes, fix)
+	}
+	return issue
+}
+
+// fixEdits returns fix as text edits.
+func (issue *Issue) fixEdits(fix interface{}) ([]TextEdit, error) {
+	switch fix := fix.(type) {
+	case TextEdit:
+		return []TextEdit{fix}, nil
+	case []TextEdit:
+		return fix, nil
+	case ast.Node:
+		var buf bytes.Buffer
+		if err := format.Node(&buf, issue.file.Fset(), fix); err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		// The printed node starts at column 1. Indent any following lines to
+		// match the line the issue starts on.
+		start := issue.Position.Start
+		line := issue.file.Line(start.Line)
+		indent := string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+
+		return []TextEdit{{
+			Start:   start.Offset,
+			End:     issue.Position.End.Offset,
+			NewText: indentLines(buf.Bytes(), indent),
+		}}, nil
+	}
+	return nil, errors.Errorf("unknown fix type %T", fix)
+}
+
+// indentLines indents each line of src after the first. Lines inside a raw
+// string or a comment are left as they are, as indenting them would change
+// the string or the comment.
+func indentLines(src []byte, indent string) string {
+	// literals holds the [start, end) offsets of the raw strings and comments
+	// spanning more than one line.
+	var literals [][2]int
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if (tok == token.STRING || tok == token.COMMENT) && strings.Contains(lit, "\n") {
+			start := file.Offset(pos)
+			literals = append(literals, [2]int{start, start + len(lit)})
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, c := range src {
+		buf.WriteByte(c)
+		if c != '\n' {
+			continue
+		}
+		for len(literals) > 0 && literals[0][1] <= i {
+			literals = literals[1:]
+		}
+		if len(literals) == 0 || i < literals[0][0] {
+			buf.WriteString(indent)
+		}
+	}
+	return buf.String()
+}
+
+// applyEdits returns src with edits applied.
+func applyEdits(src []byte, edits []TextEdit) ([]byte, error) {
+	edits = append([]TextEdit(nil), edits...)
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Start < edits[j].Start
+	})
+
+	var out []byte
+	var last int
+	for _, e := range edits {
+		if e.Start < last || e.End < e.Start || e.End > len(src) {
+			return nil, errors.Errorf("edit [%d, %d) is out of range or overlaps another edit", e.Start, e.End)
+		}
+		out = append(out, src[last:e.Start]...)
+		out = append(out, e.NewText...)
+		last = e.End
+	}
+	return append(out, src[last:]...), nil
+}
+
+// diffContext is the number of unchanged lines shown around a change.
+const diffContext = 3
+
+// unifiedDiff returns a unified diff of src with edits applied. Only the span
+// from the first to the last changed line is diffed, as one hunk.
+func unifiedDiff(filename string, src []byte, edits []TextEdit) (string, error) {
+	dst, err := applyEdits(src, edits)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if bytes.Equal(src, dst) {
+		return "", nil
+	}
+
+	a, aNewline := splitLines(src)
+	b, bNewline := splitLines(dst)
+
+	// Lines are only equal if both, or neither, end with a newline.
+	equal := func(i, j int) bool {
+		return a[i] == b[j] && (i < len(a)-1 || aNewline) == (j < len(b)-1 || bNewline)
+	}
+
+	// Trim the lines both sides have in common.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && equal(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && equal(len(a)-1-suffix, len(b)-1-suffix) {
+		suffix++
+	}
+
+	before := prefix - diffContext
+	if before < 0 {
+		before = 0
+	}
+	after := suffix - diffContext
+	if after < 0 {
+		after = 0
+	}
+	aHunk := a[before : len(a)-after]
+	bHunk := b[before : len(b)-after]
+
+	// Patch paths are relative, so an absolute filename loses its leading
+	// slash: "a/tmp/x.go" rather than "a//tmp/x.go".
+	name := strings.TrimPrefix(filepath.ToSlash(filename), "/")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- a/%s\n+++ b/%s\n", name, name)
+	fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(before, len(aHunk)), hunkRange(before, len(bHunk)))
+
+	// writeLine writes the nth line of lines, noting if it is the last line
+	// of a file without a trailing newline.
+	writeLine := func(prefix string, lines []string, n int, newline bool) {
+		buf.WriteString(prefix + lines[n] + "\n")
+		if n == len(lines)-1 && !newline {
+			buf.WriteString("\\ No newline at end of file\n")
+		}
+	}
+
+	for i := before; i < prefix; i++ {
+		writeLine(" ", a, i, aNewline)
+	}
+	for i := prefix; i < len(a)-suffix; i++ {
+		writeLine("-", a, i, aNewline)
+	}
+	for i := prefix; i < len(b)-suffix; i++ {
+		writeLine("+", b, i, bNewline)
+	}
+	for i := len(a) - suffix; i < len(a)-after; i++ {
+		writeLine(" ", a, i, aNewline)
+	}
+
+	return buf.String(), nil
+}
+
+// splitLines splits src into lines, reporting if the last line ends with a
+// newline.
+func splitLines(src []byte) ([]string, bool) {
+	if len(src) == 0 {
+		return nil, true
+	}
+	newline := src[len(src)-1] == '\n'
+	if newline {
+		src = src[:len(src)-1]
+	}
+	return strings.Split(string(src), "\n"), newline
+}
+
+// hunkRange formats the range of a hunk starting after line start (counting
+// from 0) spanning n lines.
+func hunkRange(start, n int) string {
+	if n == 0 {
+		// An empty range names the line before it.
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
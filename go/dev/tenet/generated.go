@@ -0,0 +1,40 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// generatedRegex matches the standard header of a generated file, e.g.
+//
+// // Code generated by protoc-gen-go. DO NOT EDIT.
+//
+// See https://golang.org/s/generatedcode.
+var generatedRegex = regexp.MustCompile(`^(?://|#)\s*Code generated .* DO NOT EDIT\.$`)
+
+// isGenerated returns true if the comments at the top of the file mark it as
+// generated. Older generators, such as protoc-gen-go, wrote "Code generated
+// by ..." and "DO NOT EDIT!" on separate lines, which is also accepted.
+func isGenerated(lines [][]byte) bool {
+	var codeGenerated, doNotEdit bool
+	for _, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !bytes.HasPrefix(line, []byte("//")) && !bytes.HasPrefix(line, []byte("#")) {
+			// The header ends at the first line which is not a comment.
+			break
+		}
+		if generatedRegex.Match(line) {
+			return true
+		}
+		text := bytes.TrimSpace(bytes.TrimLeft(line, "/#"))
+		codeGenerated = codeGenerated || bytes.HasPrefix(text, []byte("Code generated "))
+		doNotEdit = doNotEdit || bytes.HasPrefix(text, []byte("DO NOT EDIT"))
+	}
+	return codeGenerated && doNotEdit
+}
@@ -1,5 +1,10 @@
 package tenet
 
+import (
+	"path/filepath"
+	"strings"
+)
+
 // information about the tenet
 type Info struct {
 	Name        string
@@ -12,13 +17,99 @@ type Info struct {
 	metrics []string
 	Options []*option
 	Version string
+
+	// Extensions are the extensions, such as ".go" or ".yaml", of the files
+	// the tenet reviews. "" is for files without one, such as Dockerfile. If
+	// none are set, a Go tenet with node or package smells reviews ".go"
+	// files, and any other tenet reviews every file. Files other than Go
+	// source are reviewed as text, by line smells only.
+	Extensions []string
+
+	// IncludeGenerated opts the tenet in to reviewing generated files, which
+	// are otherwise skipped. See File.IsGenerated.
+	IncludeGenerated bool
+
+	// IncludeFiles and ExcludeFiles are globs, such as "*_test.go" or
+	// "vendor/*", of the files the tenet reviews. If IncludeFiles is set, only
+	// files matching one of its globs are reviewed. Files matching any of
+	// ExcludeFiles are not. Users can override them with the include_files
+	// and exclude_files options.
+	IncludeFiles []string
+	ExcludeFiles []string
+
+	// locales are the languages of the tenet's localized comments.
+	locales []string
+
+	// frameworkOptions are set by the user like Options, but change the
+	// behaviour of the review rather than the tenet. They are registered
+	// when the Info is set.
+	frameworkOptions []*option
 }
 
 func (b *Base) Info() *Info {
 	return b.info
 }
 
+// name returns the name of the tenet, or "" if its Info is not set.
+func (b *Base) name() string {
+	if b.info == nil {
+		return ""
+	}
+	return b.info.Name
+}
+
 func (b *Base) SetInfo(i Info) Tenet {
 	b.info = &i
+	b.registerFrameworkOptions()
+	b.registerFrameworkIssues()
 	return b
 }
+
+// reviewsFile returns true if the tenet reviews the file at path. A tenet
+// with only line smells can review any file, so without Extensions it
+// reviews every file, whatever its language.
+func (b *Base) reviewsFile(path string) bool {
+	lineOnly := len(b.astVisitors) == 0 && len(b.packageVisitors) == 0
+	return b.info.reviewsFile(path, lineOnly)
+}
+
+// reviewsFile returns true if a tenet with this Info reviews the file at
+// path. If the tenet is lineOnly, it reviews files of any extension unless
+// Extensions is set.
+func (i *Info) reviewsFile(path string, lineOnly bool) bool {
+	if i == nil {
+		return true
+	}
+	if !i.matchesFilters(path) {
+		return false
+	}
+	exts := i.Extensions
+	if len(exts) == 0 {
+		if lineOnly || !isGoLanguage(i.Language) {
+			return true
+		}
+		exts = []string{".go"}
+	}
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	for _, e := range exts {
+		if strings.EqualFold(strings.TrimPrefix(e, "."), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewsGenerated returns true if the tenet reviews generated files.
+func (i *Info) reviewsGenerated() bool {
+	return i != nil && i.IncludeGenerated
+}
+
+// isGoLanguage returns true if language, as set in Info.Language, is Go.
+// Tenets without a language are Go tenets.
+func isGoLanguage(language string) bool {
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case "", "go", "golang":
+		return true
+	}
+	return false
+}
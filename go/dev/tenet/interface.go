@@ -5,7 +5,11 @@ package tenet
 
 import (
 	"go/ast"
+	"go/scanner"
 	"go/token"
+	"go/types"
+	"regexp"
+	"sync/atomic"
 
 	"github.com/lingo-reviews/tenets/go/dev/api"
 )
@@ -38,32 +42,70 @@ type Tenet interface {
 	// Returns the value of the option.
 	RegisterOption(name, value, usage string) *string
 
+	// Typed options, parsed and validated when they are set.
+	RegisterIntOption(name string, value int, usage string) *int
+	RegisterFloatOption(name string, value float64, usage string) *float64
+	RegisterBoolOption(name string, value bool, usage string) *bool
+	RegisterStringListOption(name string, value []string, usage string) *[]string
+	RegisterRegexOption(name string, value string, usage string) *atomic.Pointer[regexp.Regexp]
+
+	// Adds a function which can be called from comment templates.
+	RegisterTemplateFunc(name string, fn interface{})
+
 	// SmellNode will smell every node that matches the type in smellNodeFunc.
 	SmellNode(f smellNodeFunc) Tenet
 
 	// SmellLine will smell every line of every file.
 	SmellLine(f smellLineFunc) Tenet
+
+	// SmellPackage will smell every package, once all of the package's files
+	// have been sent to the review.
+	SmellPackage(f smellPackageFunc) Tenet
+
+	// ReviewConcurrently smells up to workers files at once. It is opt-in, as
+	// every smell must be safe to call concurrently.
+	ReviewConcurrently(workers int) Tenet
 }
 
-// Review should only be used inside SmellNode and SmellLine.
+// Review should only be used inside SmellNode, SmellLine and SmellPackage.
 type Review interface {
 
 	// RaiseLineIssue sends the named issue (issueName) to lingo, along with
 	// the start and end lines of the issue and metadata from opts.
 	RaiseLineIssue(issueName string, start, end int, opts ...RaiseIssueOption) Review
 
+	// RaiseRangeIssue sends the named issue (issueName) to lingo, along with
+	// the exact span of the issue and metadata from opts. Columns are byte
+	// counts, starting at 1. The end column is exclusive.
+	RaiseRangeIssue(issueName string, startLine, startCol, endLine, endCol int, opts ...RaiseIssueOption) Review
+
 	// RaiseNodeIssue sends the named issue (issueName) to lingo, along with metadata from n and opts.
 	RaiseNodeIssue(issueName string, n ast.Node, opts ...RaiseIssueOption) Review
 
 	// File is the current file being reviewed.
 	File() File
 
+	// Package is the package of the current file being reviewed.
+	Package() Package
+
 	// The current smell will no longer be called at all.
 	SmellDone()
 
 	// The current smell will no longer be called for the current file.
 	SmellDoneWithFile()
 
+	// SmellNode will smell every node of the current file that matches the
+	// type in smellNodeFunc. It is run after the smells currently walking the
+	// file have finished, so a smell can find a candidate node and then check
+	// its surroundings in a second, targeted pass. It cannot be called from a
+	// package smell.
+	SmellNode(f smellNodeFunc) Review
+
+	// SmellLine will smell every line of the current file, after the smells
+	// currently walking the file have finished. It cannot be called from a
+	// package smell.
+	SmellLine(f smellLineFunc) Review
+
 	// The current file will not be smelt by this tenet again. This should
 	// only be used if it is not logical to keep looking. If you just want to
 	// limit the number of times an issue is raised, use comment contexts. e.g.
@@ -88,6 +130,37 @@ type File interface {
 
 	// Returns the FileSet this file is a member of.
 	Fset() *token.FileSet
+
+	// Returns true if the file's header marks it as generated code, e.g.
+	// "// Code generated by stringer. DO NOT EDIT.".
+	IsGenerated() bool
+}
+
+// Package represents the Go package of the current file being reviewed.
+type Package interface {
+
+	// The name of the package, as declared in its package clause.
+	Name() string
+
+	// The directory holding the package's files.
+	Dir() string
+
+	// Returns the files of this package sent to the review, in the order
+	// they were sent.
+	Files() []File
+
+	// Returns the type checked package. All files in the package's directory
+	// with the same package clause are checked together, including those
+	// not sent to the review.
+	Types() *types.Package
+
+	// Returns the type information recorded while checking the package. It
+	// is keyed on the same ast nodes passed to SmellNode.
+	TypesInfo() *types.Info
+
+	// Returns the errors found while type checking. Type information is
+	// still recorded for everything that could be resolved.
+	TypeErrors() []error
 }
 
 // --- system interfaces ----
@@ -119,6 +192,9 @@ type BaseReview interface {
 	// A chan of issues found in the review.
 	Issues() chan *Issue
 
+	// Writes every issue found so far to a baseline file at path.
+	WriteBaseline(path string) error
+
 	// have we found an issue for every context?
 	areAllContextsMatched() bool
 
@@ -148,9 +224,16 @@ type BaseTenet interface {
 // File represents a file being checked. It is intended for use by the system.
 type BaseFile interface {
 	newIssueRange(start, end int) *issueRange
+	newIssueRangeFromColumns(startLine, startCol, endLine, endCol int) *issueRange
+	newIssueRangeFromOffsets(start, end int) *issueRange
 	newIssueRangeFromNode(n ast.Node) *issueRange
 	linePosition(line int) token.Position
 	posLine(p token.Pos) []byte
 	setLines([][]byte)
+	suppressions() *suppressions
+	occurrence(key string) int
 	diff() []int64
+	newLines() map[int]bool
+	setNewLines(map[int]bool)
+	syntaxErrs() scanner.ErrorList
 }
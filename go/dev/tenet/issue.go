@@ -14,21 +14,25 @@ import (
 // Problem represents a problem in some source code.
 // Borrows from problem struct from https://github.com/golang/lint/blob/master/lint.go
 type Issue struct {
-	Name      string                 // Name is the the name of the checker that added the issue
-	Position  *issueRange            // position in source file
-	Comment   string                 // The rendered comment for this issue.
-	CommVars  map[string]interface{} // key/value pairs for use in comment template variables e.g. {{.somevarname}}
-	CtxBefore string                 // source lines before the problem line(s)
-	LineText  string                 // the source line(s)
-	CtxAfter  string                 // source lines after the problem line(s)
-	Link      string                 // (optional) the link to the style guide for the problem
-	NewCode   bool                   // When checking a diff, this indicates if the issue was found in existing or new code.
-	Err       error                  // Any err encounted while building the issue.
-	Metrics   map[string]interface{} // Any metrics that this issue was raised with
-	Tags      []string               // Any tags this issue was raised with.
-	comments  []*comment             // A slice of possible comments for this issue.
-	file      File                   // TODO(waigani) get File out of the issue struct.
-	filename  string
+	Name        string                 // Name is the the name of the checker that added the issue
+	Position    *issueRange            // position in source file
+	Comment     string                 // The rendered comment for this issue.
+	CommVars    map[string]interface{} // key/value pairs for use in comment template variables e.g. {{.somevarname}}
+	CtxBefore   string                 // source lines before the problem line(s)
+	LineText    string                 // the source line(s)
+	CtxAfter    string                 // source lines after the problem line(s)
+	Link        string                 // (optional) the link to the style guide for the problem
+	NewCode     bool                   // When checking a diff, this indicates if the issue was found in existing or new code.
+	Err         error                  // Any err encounted while building the issue.
+	Metrics     map[string]interface{} // Any metrics that this issue was raised with
+	Tags        []string               // Any tags this issue was raised with.
+	Severity    SeverityLevel          // How important the issue is.
+	Fingerprint string                 // Identifies the issue across revisions of the file. See setFingerprint.
+	Suppressed  bool                   // The issue was suppressed by a lingo:ignore comment, but the review reports suppressed issues.
+	severity    *string                // The user's severity for the issue, if they can set one.
+	comments    []*comment             // A slice of possible comments for this issue.
+	file        File                   // TODO(waigani) get File out of the issue struct.
+	filename    string
 
 	// TODO(matt, waigani) Implement this. Possibly use github.com/waigani/diffparser and github.com/waigani/astnode.
 	// The idea is:
@@ -36,6 +40,9 @@ type Issue struct {
 	// - run Lingo with --fix. If issue.CanFix, Lingo prompts the user to keep/discard the patch.
 	// - Lingo assembles patchs into one diff and, depending on flags, either applies the patch or just saves the diff to file.
 	Patch string // A diff patch resolving the issue.
+
+	Fixes []*IssueFix // Alternative fixes for the issue. The first is rendered into Patch.
+	fixes []namedFix  // The fixes set by the Fix and NamedFix options.
 }
 
 func (issue *Issue) Filename() string { // TODO(waigani) Remove this and File from issue and just use issue.filename
@@ -57,15 +64,17 @@ func (issue *Issue) setSource(iRange *issueRange) *Issue {
 func (issue *Issue) addComment(commentTemplate string, contexts ...CommentContext) {
 	com := &comment{
 		Template: commentTemplate,
-		matches:  map[CommentContext]bool{},
+		done:     map[contextPair]bool{},
 	}
 
 	// Split contexts into file and comment contexts.
-	for _, ctx := range contexts {
-		if isFileContext(ctx) {
-			com.addFileCtx(ctx)
-		} else {
-			com.addCommentCtx(ctx)
+	for _, ctxs := range contexts {
+		for _, ctx := range ctxs.split() {
+			if isFileContext(ctx) {
+				com.addFileCtx(ctx)
+			} else {
+				com.addCommentCtx(ctx)
+			}
 		}
 	}
 
@@ -82,6 +91,40 @@ func (issue *Issue) addComment(commentTemplate string, contexts ...CommentContex
 	issue.comments = append(issue.comments, com)
 }
 
+// setConfiguredComment replaces the comment configured by the user for ctx,
+// if any, with commentTemplate. Configured comments are put before the
+// tenet's own, so they are used first, and a configured default comment is
+// put after the other configured comments. An empty commentTemplate only
+// removes the configured comment.
+func (issue *Issue) setConfiguredComment(commentTemplate string, ctx CommentContext) {
+	var comments []*comment
+	var configured int
+	for _, comm := range issue.comments {
+		if comm.configured {
+			if comm.commentContexts[0] == ctx {
+				continue
+			}
+			configured++
+		}
+		comments = append(comments, comm)
+	}
+	issue.comments = comments
+	if commentTemplate == "" {
+		return
+	}
+
+	issue.addComment(commentTemplate, ctx)
+	com := issue.comments[len(issue.comments)-1]
+	com.configured = true
+
+	at := 0
+	if ctx == DefaultComment {
+		at = configured
+	}
+	copy(issue.comments[at+1:], issue.comments[at:len(issue.comments)-1])
+	issue.comments[at] = com
+}
+
 // TODO(waigani) rename getStringContext
 func getLineContext(f File, issueStartLine, issueEndLine int) (ctxBefore, ctxAfter string) {
 	buffer := 4 // TODO(waigani) make this a config.
@@ -0,0 +1,85 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"strings"
+)
+
+// AddLocalizedComment adds a comment used when the review's locale is lang,
+// e.g. "fr" or "pt-BR". Comments added with AddComment are the default,
+// used when no comment in the review's locale matches the context.
+func AddLocalizedComment(lang, comment string, ctx ...CommentContext) RegisterIssueOption {
+	return func(issue *Issue) {
+		issue.addComment(comment, ctx...)
+		issue.comments[len(issue.comments)-1].lang = normalizeLocale(lang)
+	}
+}
+
+// normalizeLocale returns the language tag in lower case with "-" as the
+// separator, so that "pt_BR" and "pt-br" are the same locale.
+func normalizeLocale(lang string) string {
+	return strings.Replace(strings.ToLower(strings.TrimSpace(lang)), "_", "-", -1)
+}
+
+// baseLanguage returns the language of the locale without its region, e.g.
+// "pt" for "pt-br".
+func baseLanguage(locale string) string {
+	return strings.SplitN(locale, "-", 2)[0]
+}
+
+// localeScore rates how well a comment in lang suits locale. A comment in
+// the locale itself is best, then one in the same language, then a default
+// comment. A comment in any other language scores 0, as it does not suit the
+// locale at all.
+func localeScore(lang, locale string) int {
+	switch {
+	case lang == "":
+		return 1
+	case lang == locale:
+		return 3
+	case baseLanguage(lang) == baseLanguage(locale):
+		return 2
+	}
+	return 0
+}
+
+// bestLocalized returns the comment in comms which best suits locale, or nil
+// if none do. A comment configured by the user beats all others. Of equally
+// suited comments, the first is returned.
+func bestLocalized(comms []*comment, locale string) *comment {
+	locale = normalizeLocale(locale)
+	var best *comment
+	var bestScore int
+	for _, comm := range comms {
+		score := localeScore(comm.lang, locale)
+		if comm.configured {
+			score = 4
+		}
+		if score > bestScore {
+			best, bestScore = comm, score
+		}
+	}
+	return best
+}
+
+// addLocales adds the languages of the issue's localized comments to the
+// locales the tenet supports.
+func (i *Info) addLocales(issue *Issue) {
+	for _, comm := range issue.comments {
+		if comm.lang == "" || containsString(i.locales, comm.lang) {
+			continue
+		}
+		i.locales = append(i.locales, comm.lang)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/lingo-reviews/tenets/go/dev/api"
+)
+
+// newLines returns the set of line numbers added to the file sent to the
+// review, or nil if the file was sent without a diff. The lines are taken
+// from, in order of preference, a unified diff of the file, the content of
+// the file before the change or the changed lines listed with the file.
+func newLines(file *api.File, lines [][]byte) (map[int]bool, error) {
+	switch {
+	case file.Diff != "":
+		added, err := parseUnifiedDiff(file.Diff)
+		return added, errors.Annotatef(err, "could not read diff of %q", file.Name)
+	case file.BaseContent != "":
+		return addedLines(bytes.Split([]byte(file.BaseContent), []byte("\n")), lines), nil
+	case len(file.Lines) > 0:
+		added := map[int]bool{}
+		for _, l := range file.Lines {
+			added[int(l)] = true
+		}
+		return added, nil
+	}
+	return nil, nil
+}
+
+// hunkRegex matches the header of a hunk in a unified diff. The groups are
+// the number of lines of the hunk in the old file, the first line of the hunk
+// in the new file and its number of lines there. Counts default to one.
+var hunkRegex = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parseUnifiedDiff returns the line numbers, in the new file, of the lines
+// added by the hunks of diff. A diff without hunks, such as one that only
+// renames the file, adds no lines.
+func parseUnifiedDiff(diff string) (map[int]bool, error) {
+	added := map[int]bool{}
+	// oldLeft and newLeft count the lines of the current hunk still to be
+	// read, so that the hunk ends when they run out.
+	var line, oldLeft, newLeft int
+	for _, l := range strings.Split(diff, "\n") {
+		if m := hunkRegex.FindStringSubmatch(l); m != nil {
+			var err error
+			if oldLeft, err = hunkCount(m[1]); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if line, err = strconv.Atoi(m[2]); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if newLeft, err = hunkCount(m[3]); err != nil {
+				return nil, errors.Trace(err)
+			}
+			continue
+		}
+		if oldLeft <= 0 && newLeft <= 0 {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(l, "+"):
+			added[line] = true
+			line++
+			newLeft--
+		case strings.HasPrefix(l, "-"):
+			oldLeft--
+		case strings.HasPrefix(l, "\\"):
+		default:
+			// A context line. Blank context lines are often stripped of
+			// their leading space, so an empty line is one too.
+			line++
+			oldLeft--
+			newLeft--
+		}
+	}
+	return added, nil
+}
+
+// hunkCount parses the count of lines in a hunk header, which is one if
+// omitted.
+func hunkCount(s string) (int, error) {
+	if s == "" {
+		return 1, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// addedLines returns the line numbers of the lines in head which are not in
+// a longest common subsequence of base and head. The subsequence is found
+// with Myers' algorithm, in space linear in the number of lines.
+func addedLines(base, head [][]byte) map[int]bool {
+	// Lines are numbered by content, so that they compare as ints.
+	ids := map[string]int{}
+	number := func(lines [][]byte) []int {
+		n := make([]int, len(lines))
+		for i, l := range lines {
+			id, ok := ids[string(l)]
+			if !ok {
+				id = len(ids)
+				ids[string(l)] = id
+			}
+			n[i] = id
+		}
+		return n
+	}
+	baseIDs, headIDs := number(base), number(head)
+
+	// A line only one side has cannot be in the common subsequence, so it is
+	// left out of the diff. This keeps the diff of unrelated files cheap.
+	inBase := map[int]bool{}
+	for _, id := range baseIDs {
+		inBase[id] = true
+	}
+	inHead := map[int]bool{}
+	for _, id := range headIDs {
+		inHead[id] = true
+	}
+	var a []int
+	for _, id := range baseIDs {
+		if inHead[id] {
+			a = append(a, id)
+		}
+	}
+	added := map[int]bool{}
+	// line holds the line number in head of each element of b.
+	var b, line []int
+	for i, id := range headIDs {
+		if !inBase[id] {
+			added[i+1] = true
+			continue
+		}
+		b = append(b, id)
+		line = append(line, i+1)
+	}
+
+	insertions(a, b, 0, func(j int) {
+		added[line[j]] = true
+	})
+	return added
+}
+
+// insertions calls insert with the index, plus off, of each element of b
+// which is inserted by a shortest edit script from a to b.
+func insertions(a, b []int, off int, insert func(int)) {
+	// Elements both sides have in common at the start and end are kept.
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b, off = a[1:], b[1:], off+1
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	if len(a) == 0 || len(b) == 0 {
+		for j := range b {
+			insert(off + j)
+		}
+		return
+	}
+	// Both sides now differ at both ends, so the script has at least two
+	// edits and the split point is strictly inside it.
+	x, y, ok := splitPoint(a, b)
+	if !ok {
+		for j := range b {
+			insert(off + j)
+		}
+		return
+	}
+	insertions(a[:x], b[:y], off, insert)
+	insertions(a[x:], b[y:], off+y, insert)
+}
+
+// splitPoint returns a point (x, y) on a shortest edit script from a to b,
+// where the script has as many edits before the point as after it, give or
+// take one. Paths are searched forwards from the start and backwards from
+// the end until they meet, keeping only the furthest point of each diagonal.
+func splitPoint(a, b []int) (x, y int, ok bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	// forward[maxD+k] is the furthest x reached on diagonal k = x - y from
+	// the start, and backward[maxD+k] the same counted from the end.
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[maxD+1], backward[maxD+1] = 0, 0
+	delta := n - m
+	// With an odd delta the paths meet on a forward step, otherwise on a
+	// backward one.
+	odd := delta%2 != 0
+	// The diagonals to skip at each end, once they have run off the edit
+	// graph.
+	var fStart, fEnd, bStart, bEnd int
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			i := maxD + k
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				j := maxD + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			i := maxD + k
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				j := maxD + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 && forward[j] >= n-x {
+					fx := forward[j]
+					return fx, fx - (delta - k), true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// parseRadius parses the new_code_radius option, which cannot be negative.
+func parseRadius(v *int) func(string) error {
+	var radius int
+	parse := parseInt(&radius)
+	return func(s string) error {
+		if err := parse(s); err != nil {
+			return err
+		}
+		if radius < 0 {
+			return errors.Errorf("expected a radius of 0 or more, got %d", radius)
+		}
+		*v = radius
+		return nil
+	}
+}
+
+// setNewCode marks the issue as new code if any of its lines were added. If
+// only new code is reported, it returns false for an issue further than the
+// new_code_radius option from any added line. Issues in files sent without a
+// diff are always reported.
+func (r *review) setNewCode(issue *Issue) bool {
+	f, ok := issue.file.(BaseFile)
+	if !ok || f.newLines() == nil {
+		return true
+	}
+	added := f.newLines()
+	start, end := issue.Position.Start.Line, issue.Position.End.Line
+	for l := start; l <= end; l++ {
+		if added[l] {
+			issue.NewCode = true
+			return true
+		}
+	}
+
+	b := r.baseTenet()
+	if b.newCodeOnly == nil || !*b.newCodeOnly {
+		return true
+	}
+	// There are usually far fewer added lines than lines in the radius.
+	radius := *b.newCodeRadius
+	for l := range added {
+		if l >= start-radius && l <= end+radius {
+			return true
+		}
+	}
+	return false
+}
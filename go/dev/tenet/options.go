@@ -0,0 +1,116 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+)
+
+// The typed options below are set from the same strings as RegisterOption,
+// but the value is parsed when the option is set. A value that does not parse
+// is rejected by MixinConfigOptions, and the option keeps its last good value.
+
+// RegisterIntOption registers an option holding an int.
+func (b *Base) RegisterIntOption(name string, value int, usage string) *int {
+	v := value
+	b.registerOption(name, strconv.Itoa(value), usage, parseInt(&v))
+	return &v
+}
+
+// RegisterFloatOption registers an option holding a float64.
+func (b *Base) RegisterFloatOption(name string, value float64, usage string) *float64 {
+	v := value
+	b.registerOption(name, strconv.FormatFloat(value, 'g', -1, 64), usage, parseFloat(&v))
+	return &v
+}
+
+// RegisterBoolOption registers an option holding a bool. It accepts the
+// values accepted by strconv.ParseBool, e.g. "true", "false", "1" or "0".
+func (b *Base) RegisterBoolOption(name string, value bool, usage string) *bool {
+	v := value
+	b.registerOption(name, strconv.FormatBool(value), usage, parseBool(&v))
+	return &v
+}
+
+// RegisterStringListOption registers an option holding a list of strings. The
+// user sets it as a comma separated list.
+func (b *Base) RegisterStringListOption(name string, value []string, usage string) *[]string {
+	v := value
+	b.registerOption(name, strings.Join(value, ","), usage, parseStringList(&v))
+	return &v
+}
+
+// RegisterRegexOption registers an option holding a regular expression. The
+// default value must compile. The expression is replaced, rather than changed,
+// when the option is set, so Load it each time it is used.
+func (b *Base) RegisterRegexOption(name string, value string, usage string) *atomic.Pointer[regexp.Regexp] {
+	v := &atomic.Pointer[regexp.Regexp]{}
+	v.Store(regexp.MustCompile(value))
+	b.registerOption(name, value, usage, parseRegex(v))
+	return v
+}
+
+func parseInt(v *int) func(string) error {
+	return func(s string) error {
+		i, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return errors.Errorf("expected an integer, got %q", s)
+		}
+		*v = i
+		return nil
+	}
+}
+
+func parseFloat(v *float64) func(string) error {
+	return func(s string) error {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return errors.Errorf("expected a number, got %q", s)
+		}
+		*v = f
+		return nil
+	}
+}
+
+func parseBool(v *bool) func(string) error {
+	return func(s string) error {
+		t, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return errors.Errorf("expected true or false, got %q", s)
+		}
+		*v = t
+		return nil
+	}
+}
+
+func parseStringList(v *[]string) func(string) error {
+	return func(s string) error {
+		var list []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*v = list
+		return nil
+	}
+}
+
+// parseRegex swaps the compiled expression into v, so a smell using the old
+// one is not changed under it.
+func parseRegex(v *atomic.Pointer[regexp.Regexp]) func(string) error {
+	return func(s string) error {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return errors.Annotatef(err, "expected a regular expression, got %q", s)
+		}
+		v.Store(re)
+		return nil
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lingo-reviews/tenets/go/dev/tenet/log"
+)
+
+// gopackage implements Package.
+type gopackage struct {
+	name string
+	dir  string
+	fset *token.FileSet
+
+	// files sent to the review that belong to this package, in the order
+	// they arrived.
+	files []File
+
+	// asts is shared with the review, so that a file parsed while type
+	// checking is not parsed again when it is sent to the review, and vice
+	// versa. The type information is keyed on these nodes.
+	asts map[string]*ast.File
+
+	// mu is the review's lock, guarding asts, files and typeCheck.
+	mu *sync.Mutex
+
+	// typeCheck is the type check of the package's files, or nil if it has
+	// not been asked for since the package's files last changed.
+	typeCheck *typeCheck
+}
+
+// typeCheck holds the result of type checking a package. A new one replaces
+// it when a file is sent with content that differs from the AST it was
+// checked with.
+type typeCheck struct {
+	once   sync.Once
+	pkg    *types.Package
+	info   *types.Info
+	errors []error
+}
+
+func packageKey(dir, name string) string {
+	return dir + ":" + name
+}
+
+func (p *gopackage) Name() string {
+	return p.name
+}
+
+func (p *gopackage) Dir() string {
+	return p.dir
+}
+
+func (p *gopackage) Files() []File {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]File(nil), p.files...)
+}
+
+func (p *gopackage) addFile(f File) {
+	for _, file := range p.files {
+		if file.Filename() == f.Filename() {
+			return
+		}
+	}
+	p.files = append(p.files, f)
+}
+
+// fileAt returns the reviewed file in this package containing pos, or nil.
+func (p *gopackage) fileAt(pos token.Pos) File {
+	for _, f := range p.files {
+		if f.AST() == nil {
+			continue
+		}
+		tf := p.fset.File(f.AST().Pos())
+		if tf != nil && tf.Base() <= int(pos) && int(pos) <= tf.Base()+tf.Size() {
+			return f
+		}
+	}
+	return nil
+}
+
+func (p *gopackage) Types() *types.Package {
+	return p.check().pkg
+}
+
+func (p *gopackage) TypesInfo() *types.Info {
+	return p.check().info
+}
+
+func (p *gopackage) TypeErrors() []error {
+	return p.check().errors
+}
+
+// check type checks every file in the package's directory with the same
+// package clause. It is only done once, and only if a smell asks for type
+// information, unless the check is reset.
+func (p *gopackage) check() *typeCheck {
+	p.mu.Lock()
+	if p.typeCheck == nil {
+		p.typeCheck = &typeCheck{}
+	}
+	tc := p.typeCheck
+	p.mu.Unlock()
+
+	tc.once.Do(func() { p.doCheck(tc) })
+	return tc
+}
+
+// resetCheck drops the package's type check, so that it is checked again
+// with the current ASTs of its files. p.mu must be held.
+func (p *gopackage) resetCheck() {
+	p.typeCheck = nil
+}
+
+func (p *gopackage) doCheck(tc *typeCheck) {
+	files := p.loadFiles()
+	tc.info = &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Implicits:  map[ast.Node]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+		Scopes:     map[ast.Node]*types.Scope{},
+	}
+	conf := types.Config{
+		Importer: importer.Default(),
+		// Keep checking after an error. Tenets work with whatever could be
+		// resolved.
+		Error: func(err error) {
+			tc.errors = append(tc.errors, err)
+		},
+	}
+
+	// The returned error is the first of tc.errors.
+	tc.pkg, _ = conf.Check(filepath.ToSlash(p.dir), p.fset, files, tc.info)
+}
+
+// loadFiles returns the ASTs of all files that make up the package, sorted by
+// filename. Files not yet sent to the review are parsed from disk.
+func (p *gopackage) loadFiles() []*ast.File {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	infos, err := ioutil.ReadDir(p.dir)
+	if err != nil {
+		log.Printf("could not read package dir %q: %v", p.dir, err)
+	}
+
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") {
+			continue
+		}
+		path := filepath.Join(p.dir, info.Name())
+		if _, ok := p.asts[path]; ok {
+			continue
+		}
+		f, err := parser.ParseFile(p.fset, path, nil, parser.ParseComments)
+		if err != nil {
+			log.Printf("could not parse package file %q: %v", path, err)
+			continue
+		}
+		p.asts[path] = f
+	}
+
+	var names []string
+	for path, f := range p.asts {
+		if filepath.Dir(path) == p.dir && f.Name.Name == p.name {
+			names = append(names, path)
+		}
+	}
+	sort.Strings(names)
+
+	files := make([]*ast.File, len(names))
+	for i, name := range names {
+		files[i] = p.asts[name]
+	}
+	return files
+}
@@ -1,19 +1,18 @@
 package tenet
 
 import (
-	"bytes"
 	"fmt"
 	"go/ast"
 	"go/token"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
 
-	"text/template"
-
 	"github.com/lingo-reviews/tenets/go/dev/api"
 	"github.com/lingo-reviews/tenets/go/dev/tenet/log"
 )
@@ -28,8 +27,34 @@ type review struct {
 	file       File
 	issueOrder *issueOrder
 
-	// a scratch dir for artefacts while reviewing.
-	tmpdir string
+	// fset is shared by all files in the review.
+	fset *token.FileSet
+
+	// asts holds every Go file parsed during the review, by filename.
+	asts map[string]*ast.File
+
+	// packages holds the packages of the files reviewed so far, keyed by
+	// directory and package name.
+	packages map[string]*gopackage
+
+	// packageOrder is the order in which packages were first seen.
+	packageOrder []*gopackage
+
+	// mu guards asts, packages, packageOrder and tmpdir, which are shared by
+	// the copies of a review smelling files concurrently.
+	mu *sync.Mutex
+
+	// dispatch maps node types to the tenet's astVisitors. If nil, the
+	// tenet's own table is used.
+	dispatch *nodeDispatch
+
+	// If buffer is true, raised issues are held in buffered rather than
+	// sent. See reviewConcurrently.
+	buffer   bool
+	buffered []*Issue
+
+	// a scratch dir for artefacts while reviewing, created on first use.
+	tmpdir *string
 
 	fileDone func()
 
@@ -40,6 +65,20 @@ type review struct {
 	smellDoneWithFile func()
 
 	fileDoneMap map[string]bool
+
+	// nested smells, registered while smelling the current file.
+	astVisitors  astVisitors
+	lineVisitors lineVisitors
+
+	// smellingPackages is true while package smells run. There is no file
+	// walk left to run nested smells in then.
+	smellingPackages bool
+
+	// known is the baseline loaded for the review, if any.
+	known *baseline
+
+	// found records every issue released, to write a baseline from.
+	found *baseline
 }
 
 // StartReview listens for files sent to r.SendFile(filename) and reviews them.
@@ -48,10 +87,15 @@ func (r *review) StartReview() {
 		defer r.Close()
 		log.Println("started review")
 		b := base(r.tenet)
+		r.startBaseline()
+
+		if b.workers > 1 {
+			r.reviewConcurrently(b.workers)
+			return
+		}
 
 		// check files synchronously to ensure correct ordering and that we stop
 		// after the context is full.
-		fset := token.NewFileSet()
 		log.Println("reading off filesc")
 
 		for {
@@ -59,15 +103,25 @@ func (r *review) StartReview() {
 			case file, ok := <-r.filesc:
 				if !ok && file == nil {
 					log.Println("all files reviewed.")
+					r.smellPackages()
+					r.endBaseline()
 					return
 				}
 
-				f, err := buildFile(file.Name, "", fset, file.Lines)
+				if !b.reviewsFile(file.Name) {
+					log.Printf("skipping file %q: it does not match the tenet's extensions or file filters", file.Name)
+					continue
+				}
+				f, err := r.buildFile(file)
 				if err != nil {
 					log.Println("could not build file")
 					b.SendError(errors.Annotatef(err, "could not find file: %q", file))
 					continue
 				}
+				if f == nil {
+					log.Println("skipping generated file", file)
+					continue
+				}
 				log.Println("checking file", file)
 				err = r.check(f)
 				b.addErrOnErr(err, f, 0)
@@ -80,6 +134,159 @@ func (r *review) StartReview() {
 	}()
 }
 
+// buildFile builds the file sent to the review and adds it to its package.
+// It returns nil if the file is generated and the tenet does not review
+// generated files.
+func (r *review) buildFile(file *api.File) (File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// The file is only read from disk if it was sent without its content.
+	src, err := fileContent(file)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	_, parsed := r.asts[file.Name]
+	f, err := buildFile(file.Name, src, r.fset, r.asts, file.Lines)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if parsed && src != nil {
+		// The file was parsed from disk to type check its package, so the
+		// packages of its dir are checked again with the content sent.
+		r.resetChecks(filepath.Dir(file.Name))
+	}
+	added, err := newLines(file, f.Lines())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	f.(BaseFile).setNewLines(added)
+	if f.IsGenerated() && !r.baseTenet().info.reviewsGenerated() {
+		return nil, nil
+	}
+	r.addToPackage(f)
+	return f, nil
+}
+
+// reviewConcurrently reviews files on a pool of workers. Each file is smelt
+// by a copy of the review which buffers the issues raised. The buffered
+// issues are then released in the order the files were sent, so comment
+// contexts resolve as they would in a sequential review.
+func (r *review) reviewConcurrently(workers int) {
+	b := r.baseTenet()
+
+	type job struct {
+		n    int
+		file File
+	}
+	type result struct {
+		n      int
+		issues []*Issue
+	}
+	jobs := make(chan job)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	wg.Add(workers + 1)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			// The dispatch table is filled in as nodes are walked, so each
+			// worker keeps its own.
+			d := newNodeDispatch(b.astVisitors)
+			for j := range jobs {
+				fr := r.fileReview(d)
+				b.addErrOnErr(fr.check(j.file), j.file, 0)
+				results <- result{j.n, fr.buffered}
+			}
+		}()
+	}
+
+	// Build each file as it arrives and hand it to the workers.
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for n := 0; ; n++ {
+			select {
+			case file, ok := <-r.filesc:
+				if !ok && file == nil {
+					log.Println("all files sent to workers.")
+					return
+				}
+
+				if !b.reviewsFile(file.Name) {
+					log.Printf("skipping file %q: it does not match the tenet's extensions or file filters", file.Name)
+					results <- result{n: n}
+					continue
+				}
+				f, err := r.buildFile(file)
+				if err != nil {
+					log.Println("could not build file")
+					b.SendError(errors.Annotatef(err, "could not find file: %q", file))
+
+					// Nothing to release for this file.
+					results <- result{n: n}
+					continue
+				}
+				if f == nil {
+					log.Println("skipping generated file", file)
+					results <- result{n: n}
+					continue
+				}
+				jobs <- job{n, f}
+			case <-time.After(3 * time.Second):
+				b.errorsc <- errors.New("timed out waiting for file")
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Release each file's issues in order.
+	pending := map[int][]*Issue{}
+	var next int
+	for res := range results {
+		pending[res.n] = res.issues
+		for {
+			issues, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			for _, issue := range issues {
+				r.releaseIssue(issue)
+			}
+			next++
+		}
+	}
+
+	log.Println("all files reviewed.")
+	r.smellPackages()
+	r.endBaseline()
+}
+
+// fileReview returns a copy of the review to smell one file on a worker.
+// Issues raised by the copy are buffered until they are released by the
+// review.
+func (r *review) fileReview(d *nodeDispatch) *review {
+	return &review{
+		tenet: r.tenet,
+		fset:  r.fset,
+		asts:  r.asts,
+		// Files are added to their packages as they are built, so the copy
+		// only ever looks packages up.
+		packages:    r.packages,
+		mu:          r.mu,
+		tmpdir:      r.tmpdir,
+		fileDoneMap: map[string]bool{},
+		dispatch:    d,
+		buffer:      true,
+	}
+}
+
 func (r *review) SendFile(file *api.File) {
 	r.filesc <- file
 }
@@ -92,8 +299,10 @@ func (r *review) EndReview() {
 func (r *review) Close() {
 	if r.waitc != nil {
 		log.Println("closing review and waitc")
+		if *r.tmpdir != "" {
+			os.RemoveAll(*r.tmpdir)
+		}
 		close(r.waitc)
-		os.RemoveAll(r.tmpdir)
 		r.waitc = nil
 	}
 }
@@ -122,55 +331,101 @@ func (r *review) check(f File) error {
 		r.fileDoneMap[f.Filename()] = true
 	}
 
-	// TODO(waigani) this should be r.recursiveASTWalk()
-	for _, visitor := range b.astVisitors {
-		r.walkAST(&visitor)
-	}
+	// Nested smells are scoped to the file they were registered in.
+	r.astVisitors = nil
+	r.lineVisitors = nil
+
+	r.raiseSyntaxErrors(f)
 
 	// first walk all ast nodes.
-	// r.recursiveASTWalk(b, f)
+	d := r.dispatch
+	if d == nil {
+		d = b.nodeDispatch()
+	}
+	r.walkAST(b.astVisitors, d)
 
-	// TODO(waigani) support recursive line visits.
 	if len(b.lineVisitors) > 0 {
 		// then check all src lines
-		r.visitLines()
+		r.visitLines(b.lineVisitors)
 	}
 
+	// finally run any smells registered by the smells above.
+	r.recursiveWalk()
+
 	return nil
 }
 
-// TODO(waigani) Get this working. We need to group astVisitors by filename.
-// Top level smells will be added to b.astVisitor.
-// Nested smells will be added with r.SmellNode
-// They will be added to the file collection of visitors: r.astVisitors[filename]
-// That collection will be used in the recursiveASTWallk func
-//
-// Slices are only read once at the beginning of a loop, so we need to use a
-// recursive func to update the collection. At the end of the walks, the
-// original range is removed. If new visitors were added during the walks,
-// they are then run.
-// func (r *review) recursiveASTWalk() {
-// 	xxx.Print(r.File().Filename())
-// 	l := len(b.astVisitors)
-// 	for _, visitor := range visitors {
-// 		r.walkAST(&visitor)
-// 	}
-
-// 	b.astVisitors.deleteRange(0, l-1)
-// 	if len(b.astVisitors) > 0 {
-// 		r.recursiveASTWalk(b, f)
-// 	}
-// }
+// raiseSyntaxErrors raises a syntax_error issue at each syntax error found
+// parsing f.
+func (r *review) raiseSyntaxErrors(f File) {
+	bf, ok := f.(BaseFile)
+	if !ok {
+		return
+	}
+	for _, err := range bf.syntaxErrs() {
+		p := err.Pos
+		r.raiseIssue(syntaxErrorIssue, f, bf.newIssueRangeFromColumns(p.Line, p.Column, p.Line, p.Column),
+			[]RaiseIssueOption{CommentVar("error", err.Msg)})
+	}
+}
+
+// recursiveWalk runs the nested smells added with r.SmellNode and
+// r.SmellLine. Slices are only read once at the beginning of a loop, so we
+// take the current collection and reset it before walking. If new visitors
+// were added during the walks, they are then run.
+func (r *review) recursiveWalk() {
+	astVisitors, lineVisitors := r.astVisitors, r.lineVisitors
+	if len(astVisitors) == 0 && len(lineVisitors) == 0 {
+		return
+	}
+	r.astVisitors, r.lineVisitors = nil, nil
+
+	r.walkAST(astVisitors, newNodeDispatch(astVisitors))
+	if len(lineVisitors) > 0 {
+		r.visitLines(lineVisitors)
+	}
+
+	r.recursiveWalk()
+}
+
+// SmellNode adds a smell to be run over the current file, once the smells
+// currently walking it have finished.
+func (r *review) SmellNode(f smellNodeFunc) Review {
+	r.checkNotSmellingPackages("SmellNode")
+	r.astVisitors = append(r.astVisitors, reflectASTVisitor(f))
+	return r
+}
+
+// SmellLine adds a smell to be run over the lines of the current file, once
+// the smells currently walking it have finished.
+func (r *review) SmellLine(f smellLineFunc) Review {
+	r.checkNotSmellingPackages("SmellLine")
+	r.lineVisitors = append(r.lineVisitors, lineVisitor{
+		visit: f,
+	})
+	return r
+}
+
+// checkNotSmellingPackages panics if a nested smell is registered from a
+// package smell, where it would never run.
+func (r *review) checkNotSmellingPackages(name string) {
+	if r.smellingPackages {
+		// Yes panic, this is a developer error.
+		panic(fmt.Sprintf("%s cannot be called from a package smell", name))
+	}
+}
 
 func (r *review) baseTenet() *Base {
 	return r.tenet.(BaseTenet).base()
 }
 
 func (r *review) TMPDIR() (_ string, err error) {
-	if r.tmpdir == "" {
-		r.tmpdir, err = ioutil.TempDir(os.TempDir(), "tenet_review_"+RandString(5))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if *r.tmpdir == "" {
+		*r.tmpdir, err = ioutil.TempDir(os.TempDir(), "tenet_review_"+RandString(5))
 	}
-	return r.tmpdir, err
+	return *r.tmpdir, err
 }
 
 // --- visitor methods ---
@@ -187,17 +442,19 @@ func (r *review) FileDone() {
 	r.fileDone()
 }
 
+// lineVisitor smells each line. It is copied for each file walked, so done
+// and doneWithFile only last until the end of the walk.
 type lineVisitor struct {
-	fileDone map[string]bool
-	done     bool
-	visit    smellLineFunc
+	done         bool
+	doneWithFile bool
+	visit        smellLineFunc
 }
 
 type smellLineFunc func(r Review, n int, line []byte) error
 
 func (l *lineVisitor) Visit(r Review, n int, line []byte) error {
 	r.(*review).smellDoneWithFile = func() {
-		l.fileDone[r.File().Filename()] = true
+		l.doneWithFile = true
 	}
 	r.(*review).smellDone = func() {
 		l.done = true
@@ -205,8 +462,8 @@ func (l *lineVisitor) Visit(r Review, n int, line []byte) error {
 	return l.visit(r, n, line)
 }
 
-func (l *lineVisitor) isSmellDoneWithFile(filename string) bool {
-	return l.fileDone[filename]
+func (l *lineVisitor) isSmellDoneWithFile() bool {
+	return l.doneWithFile
 }
 
 func (l *lineVisitor) isSmellDone() bool {
@@ -222,12 +479,12 @@ func lineInDiff(diff []int64, lineNo int64) bool {
 	return false
 }
 
-func (r *review) visitLines() {
+func (r *review) visitLines(visitors lineVisitors) {
 	b := r.baseTenet()
 	f := r.File()
 	fName := f.Filename()
 
-	for _, v := range b.lineVisitors {
+	for _, v := range visitors {
 		for i, line := range f.Lines() {
 
 			diff := f.(BaseFile).diff()
@@ -235,7 +492,7 @@ func (r *review) visitLines() {
 				continue
 			}
 
-			if v.isSmellDoneWithFile(fName) || v.isSmellDone() || r.isFileDone(fName) {
+			if v.isSmellDoneWithFile() || v.isSmellDone() || r.isFileDone(fName) {
 				break
 			}
 			n := i + 1
@@ -248,82 +505,184 @@ func (r *review) isFileDone(filename string) bool {
 	return r.fileDoneMap[filename]
 }
 
-// astVisitor walks through each AST node.
+// astVisitor smells each AST node of one type. It is copied for each file
+// walked, so done and doneWithFile only last until the end of the walk.
 type astVisitor struct {
-	done      bool
-	fileDone  map[string]bool
-	visit     func(node ast.Node) (w ast.Visitor)
-	smellNode smellNodeFunc
+	done         bool
+	doneWithFile bool
+
+	// nodeType is the type of node smelt. If it is an interface, every node
+	// implementing it is smelt.
+	nodeType reflect.Type
+	smell    func(r Review, node ast.Node) error
 }
 
-// TODO(waigani) should be of type: func(Review, ast.Node) error
+// smellNodeFunc is a func(Review, <ast node type>) error, such as
+// func(Review, *ast.FuncDecl) error. Prefer OnNode, which is checked by the
+// compiler.
 type smellNodeFunc interface{}
 
-func (v *astVisitor) Visit(node ast.Node) (w ast.Visitor) {
-	return v.visit(node)
+func newASTVisitor(nodeType reflect.Type, smell func(Review, ast.Node) error) astVisitor {
+	return astVisitor{
+		nodeType: nodeType,
+		smell:    smell,
+	}
+}
+
+var (
+	reviewType  = reflect.TypeOf((*Review)(nil)).Elem()
+	astNodeType = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// reflectASTVisitor checks the signature of f once, when the smell is
+// registered, and wraps it in a func that can be called for every matching
+// node.
+func reflectASTVisitor(f smellNodeFunc) astVisitor {
+	fV := reflect.ValueOf(f)
+	fT := fV.Type()
+	if fT.Kind() != reflect.Func ||
+		fT.NumIn() != 2 || fT.In(0) != reviewType || !fT.In(1).Implements(astNodeType) ||
+		fT.NumOut() > 1 || (fT.NumOut() == 1 && fT.Out(0) != errorType) {
+		// Yes panic, this is a developer error.
+		panic(fmt.Sprintf("SmellNode expects a func(tenet.Review, <ast.Node>) error, got %s", fT))
+	}
+
+	return newASTVisitor(fT.In(1), func(r Review, node ast.Node) error {
+		refV := fV.Call([]reflect.Value{reflect.ValueOf(r), reflect.ValueOf(node)})
+		if len(refV) > 0 {
+			if err, _ := refV[0].Interface().(error); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// smells returns true if node is of the type this visitor smells.
+func (v *astVisitor) smells(node ast.Node) bool {
+	if v.nodeType.Kind() == reflect.Interface {
+		return reflect.TypeOf(node).Implements(v.nodeType)
+	}
+	return reflect.TypeOf(node) == v.nodeType
 }
 
 func (v *astVisitor) isSmellDone() bool {
 	return v.done
 }
 
-func (v *astVisitor) isSmellDoneWithFile(filename string) bool {
-	return v.fileDone[filename]
+func (v *astVisitor) isSmellDoneWithFile() bool {
+	return v.doneWithFile
 }
 
-func (r *review) walkAST(v *astVisitor) {
-	v.visit = func(node ast.Node) (w ast.Visitor) {
-		file := r.File()
-		// TODO(waigani) quick hack to get diff working. Come back and work out what's going on with diff?
-		if node == nil || v.isSmellDone() || !nodeInDiff(file.(BaseFile), node) {
-			// Keep walking other nodes.
-			return v
+// nodeDispatch maps each concrete node type to the index of every visitor
+// smelling it, in the order the visitors were registered. A type's entry is
+// computed the first time a node of that type is walked.
+type nodeDispatch struct {
+	visitors astVisitors
+	table    map[reflect.Type][]int
+}
+
+func newNodeDispatch(visitors astVisitors) *nodeDispatch {
+	return &nodeDispatch{
+		visitors: visitors,
+		table:    map[reflect.Type][]int{},
+	}
+}
+
+func (d *nodeDispatch) lookup(node ast.Node) []int {
+	t := reflect.TypeOf(node)
+	idxs, ok := d.table[t]
+	if !ok {
+		for i := range d.visitors {
+			if d.visitors[i].smells(node) {
+				idxs = append(idxs, i)
+			}
 		}
+		d.table[t] = idxs
+	}
+	return idxs
+}
+
+// matchNodes walks the current file once and returns, for each visitor, the
+// nodes it smells in walk order. As with ast.Walk, once a visitor of a node
+// type matches a node, it does not see any of that node's children. A visitor
+// of an interface, such as ast.Expr, sees every node implementing it, however
+// deeply nested.
+func (r *review) matchNodes(d *nodeDispatch) [][]ast.Node {
+	file := r.File()
+	matches := make([][]ast.Node, len(d.visitors))
+
+	// matchedAt holds the depth of the node each visitor last matched, or -1.
+	matchedAt := make([]int, len(d.visitors))
+	for i := range matchedAt {
+		matchedAt[i] = -1
+	}
 
-		fName := file.Filename()
-		if v.isSmellDoneWithFile(fName) || r.isFileDone(fName) {
-			// Stop walking all nodes.
-			return nil
+	var depth int
+	ast.Inspect(file.AST(), func(node ast.Node) bool {
+		if node == nil {
+			// We've walked all children of the node at this depth.
+			depth--
+			for i, at := range matchedAt {
+				if at == depth {
+					matchedAt[i] = -1
+				}
+			}
+			return true
 		}
 
-		funcType := reflect.TypeOf(v.smellNode)
-		nodeType := funcType.In(1)
-		if nodeType == nil {
-			panic("AST Visitor function signature does not have the right format")
+		idxs := d.lookup(node)
+		// TODO(waigani) quick hack to get diff working. Come back and work out what's going on with diff?
+		if len(idxs) > 0 && nodeInDiff(file.(BaseFile), node) {
+			for _, i := range idxs {
+				if matchedAt[i] == -1 {
+					matches[i] = append(matches[i], node)
+					if d.visitors[i].nodeType.Kind() != reflect.Interface {
+						matchedAt[i] = depth
+					}
+				}
+			}
 		}
-		expectedType := fmt.Sprintf("%T", node)
-		obtainedType := nodeType.String()
+		depth++
+		return true
+	})
+	return matches
+}
 
-		// If the type of node ast.Walk is visiting matches the type of node
-		// in the astVisitor func, call the func with the node.
-		if obtainedType == expectedType {
+// walkAST walks the current file once, then calls each visitor with the nodes
+// it smells. Each visitor smells all of its nodes before the next visitor is
+// called, in the order the visitors were registered.
+func (r *review) walkAST(visitors astVisitors, d *nodeDispatch) {
+	// Text files have no AST to walk.
+	if len(visitors) == 0 || r.File().AST() == nil {
+		return
+	}
+	matches := r.matchNodes(d)
+	fName := r.File().Filename()
+
+	for i, visitor := range visitors {
+		v := &visitor
+		for _, node := range matches[i] {
+			if v.isSmellDone() || v.isSmellDoneWithFile() || r.isFileDone(fName) {
+				break
+			}
 
 			// set review funcs
 			r.smellDoneWithFile = func() {
-				v.fileDone[r.File().Filename()] = true
+				v.doneWithFile = true
 			}
 
 			r.smellDone = func() {
 				v.done = true
 			}
 
-			// why doesn't type conversion work? Does it work in later versions of Go?
-			// wish this worked: visitorFunc.(func(Review, ast.Node))(r, node)
-			f := reflect.ValueOf(v.smellNode)
-			rV := reflect.ValueOf(r)
-			nodeV := reflect.ValueOf(node)
-			refV := f.Call([]reflect.Value{rV, nodeV})
-			if len(refV) > 0 {
-				if err := refV[0].Interface(); err != nil {
-					b := r.baseTenet()
-					b.SendError(err.(error))
-				}
+			if err := v.smell(r, node); err != nil {
+				b := r.baseTenet()
+				b.SendError(err)
 			}
-			return
 		}
-		return v
 	}
-	ast.Walk(v, r.File().AST())
 }
 
 func nodeInDiff(f BaseFile, node ast.Node) bool {
@@ -349,22 +708,139 @@ func (r *review) File() File {
 	return r.file
 }
 
+func (r *review) Package() Package {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p := r.packageOf(r.File()); p != nil {
+		return p
+	}
+	return nil
+}
+
+// packageOf returns the package f belongs to, or nil if f is not a Go file.
+// r.mu must be held.
+func (r *review) packageOf(f File) *gopackage {
+	if f == nil || f.AST() == nil {
+		return nil
+	}
+
+	dir := filepath.Dir(f.Filename())
+	name := f.AST().Name.Name
+	key := packageKey(dir, name)
+	p, ok := r.packages[key]
+	if !ok {
+		p = &gopackage{
+			name: name,
+			dir:  dir,
+			fset: r.fset,
+			asts: r.asts,
+			mu:   r.mu,
+		}
+		r.packages[key] = p
+		r.packageOrder = append(r.packageOrder, p)
+	}
+	return p
+}
+
+// resetChecks resets the type checks of the packages in dir. r.mu must be
+// held.
+func (r *review) resetChecks(dir string) {
+	for _, p := range r.packageOrder {
+		if p.dir == dir {
+			p.resetCheck()
+		}
+	}
+}
+
+// addToPackage groups f with the other files of its package. r.mu must be
+// held.
+func (r *review) addToPackage(f File) {
+	if p := r.packageOf(f); p != nil {
+		p.addFile(f)
+	}
+}
+
+// packageVisitor smells each package. It is copied for each review, so done
+// only lasts until the end of the review.
+type packageVisitor struct {
+	done  bool
+	visit smellPackageFunc
+}
+
+type smellPackageFunc func(r Review, p Package) error
+
+// smellPackages runs every package smell over each package, in the order the
+// packages were first seen. It is called once all files have been sent to
+// the review.
+func (r *review) smellPackages() {
+	b := r.baseTenet()
+	visitors := append(packageVisitors(nil), b.packageVisitors...)
+	r.smellingPackages = true
+	for _, p := range r.packageOrder {
+		if len(p.files) == 0 {
+			continue
+		}
+		for i := range visitors {
+			v := &visitors[i]
+			if v.done || r.IsClosed() {
+				continue
+			}
+
+			// Line issues raised by a package smell are raised against the
+			// package's first file. Node issues are raised against the
+			// file holding the node.
+			r.file = p.files[0]
+			r.smellDone = func() {
+				v.done = true
+			}
+			r.smellDoneWithFile = func() {}
+			r.fileDone = func() {}
+
+			if err := v.visit(r, p); err != nil {
+				b.SendError(err)
+			}
+		}
+	}
+}
+
 func (r *review) RaiseNodeIssue(issueName string, n ast.Node, opts ...RaiseIssueOption) Review {
-	return r.raiseIssue(issueName, r.File().(BaseFile).newIssueRangeFromNode(n), opts)
+	f := r.fileOfNode(n)
+	return r.raiseIssue(issueName, f, f.(BaseFile).newIssueRangeFromNode(n), opts)
 }
 
 func (r *review) RaiseLineIssue(issueName string, start, end int, opts ...RaiseIssueOption) Review {
-	return r.raiseIssue(issueName, r.File().(BaseFile).newIssueRange(start, end), opts)
+	f := r.File()
+	return r.raiseIssue(issueName, f, f.(BaseFile).newIssueRange(start, end), opts)
+}
+
+func (r *review) RaiseRangeIssue(issueName string, startLine, startCol, endLine, endCol int, opts ...RaiseIssueOption) Review {
+	f := r.File()
+	return r.raiseIssue(issueName, f, f.(BaseFile).newIssueRangeFromColumns(startLine, startCol, endLine, endCol), opts)
+}
+
+// fileOfNode returns the file holding n. This is the current file, unless n
+// was found by a package smell in another file of the package.
+func (r *review) fileOfNode(n ast.Node) File {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p := r.packageOf(r.File()); p != nil {
+		if f := p.fileAt(n.Pos()); f != nil {
+			return f
+		}
+	}
+	return r.File()
 }
 
-func (r *review) raiseIssue(issueName string, iRange *issueRange, opts []RaiseIssueOption) Review {
-	if r.areAllContextsMatched() {
+func (r *review) raiseIssue(issueName string, f File, iRange *issueRange, opts []RaiseIssueOption) Review {
+	// Contexts are only matched as issues are released. See releaseIssue.
+	// A baseline being written needs every issue.
+	if !r.buffer && r.areAllContextsMatched() && !r.baseTenet().writesBaseline() {
 		return r
 	}
 
 	b := r.baseTenet()
 	// TODO(waigani) error handle this.
-	i := b.registeredIssues[issueName]
+	i := b.issue(issueName)
 	if i == nil {
 		// Yes panic, this is a developer error.
 		msg := fmt.Sprintf("issue %q cannot be raised before it is registered", issueName)
@@ -374,6 +850,10 @@ func (r *review) raiseIssue(issueName string, iRange *issueRange, opts []RaiseIs
 	x := *i
 	x.copyTo(issue)
 
+	if issue.severity != nil {
+		issue.Severity = SeverityLevel(*issue.severity)
+	}
+
 	// TODO(waigani) This a Go blemish. Is there a nicer way to copy a struct with a map?
 	issue.CommVars = map[string]interface{}{}
 	for _, opt := range opts {
@@ -381,14 +861,52 @@ func (r *review) raiseIssue(issueName string, iRange *issueRange, opts []RaiseIs
 	}
 
 	// TODO(waigani) this is a quick hack. We need to pull File out of *Issue.
-	issue.file = r.File()
-	issue.setSource(iRange)
+	issue.file = f
+	issue.setSource(iRange).setFixes().setFingerprint(b.name())
+
+	// Issues away from new code are dropped when only new code is reviewed.
+	if !r.setNewCode(issue) {
+		return r
+	}
+
+	if r.buffer {
+		r.buffered = append(r.buffered, issue)
+		return r
+	}
+	r.releaseIssue(issue)
+	return r
+}
+
+// releaseIssue sets the contextual comment on the issue and sends it. Issues
+// must be released in the order they were found.
+func (r *review) releaseIssue(issue *Issue) {
+	// A suppressed issue does not use up a comment context.
+	if r.isSuppressed(issue) {
+		if !r.baseTenet().reportsSuppressed() || r.areAllContextsMatched() {
+			return
+		}
+		issue.Suppressed = true
+		if len(issue.comments) > 0 {
+			var err error
+			if issue.Comment, err = r.baseTenet().buildComment(issue.comments[0].Template, issue.CommVars); err != nil {
+				issue.Err = err
+			}
+		}
+		r.sendIssue(issue)
+		return
+	}
+
+	// Known issues, in the baseline, are recorded but not raised.
+	fp := r.found.add(issue)
+	if r.inBaseline(fp) || r.areAllContextsMatched() {
+		return
+	}
 
 	if err := r.setContextualComment(issue); err != nil {
 		// If no comment has been set for the context in which this issue was
 		// found, don't raise it.
 		if err == errNoCommentForContext {
-			return r
+			return
 		}
 		issue.Err = err
 	}
@@ -397,13 +915,12 @@ func (r *review) raiseIssue(issueName string, iRange *issueRange, opts []RaiseIs
 	r.sendIssue(issue)
 	log.Println("not blocked")
 
-	if r.areAllContextsMatched() {
+	// A baseline being written needs every file, so the review is left open.
+	if r.areAllContextsMatched() && !r.baseTenet().writesBaseline() {
 
-		// This is our last issue raised, close the issue chan. Note: if a
-		// tenet reviews async, this will become a race condition.
+		// This is our last issue raised, close the issue chan.
 		r.Close()
 	}
-	return r
 }
 
 func (self *Issue) copyTo(newIssue *Issue) {
@@ -446,37 +963,32 @@ func (r *review) getIssueOrder() *issueOrder {
 	return r.issueOrder
 }
 
+// isSuppressed returns true if a lingo:ignore or lingo:file-ignore comment
+// in the issue's file suppresses it.
+func (r *review) isSuppressed(issue *Issue) bool {
+	f, ok := issue.file.(BaseFile)
+	if !ok {
+		return false
+	}
+	return f.suppressions().suppresses(r.baseTenet().name(), issue.Name, issue.Position.Start.Line)
+}
+
 // setContextualComment applies the contextual comment to this issue and
 // updates its internal state of context to apply to the next issue. It
 // assumes issues will come in synchronously.
 func (r *review) setContextualComment(issue *Issue) error {
 	o := r.getIssueOrder()
 	issueName := issue.Name
-	filename := r.File().Filename()
+	filename := issue.file.Filename()
 	o.increment(issueName, filename)
 
-	fileCtx := r.currentFileContext(issue.Name)
-	commentInFileCtx := commContext[o.issueInFileCount[issueName][filename]]
-	commentInOverallCtx := commContext[o.issueCount[issueName]]
+	fileOrder := o.fileOrder[issueName][filename]
+	inFile := o.issueInFileCount[issueName][filename]
+	overall := o.issueCount[issueName]
 
 	var foundComments []*comment
 	for _, comm := range issue.comments {
-
-		var found bool
-		// does the comment match an in-file context?
-		foundFileCtx := fileCtx | commentInFileCtx | InEveryFile | DefaultComment
-		if comm.matchesContext(foundFileCtx) {
-			found = true
-			comm.addMatch(fileCtx)
-		}
-		// does the comment match an overall context?
-		foundOverallCtx := InOverall | commentInOverallCtx | DefaultComment
-		if comm.matchesContext(foundOverallCtx) {
-			found = true
-			comm.addMatch(foundOverallCtx)
-		}
-
-		if found {
+		if comm.match(fileOrder, inFile, overall) {
 			foundComments = append(foundComments, comm)
 		}
 	}
@@ -485,30 +997,18 @@ func (r *review) setContextualComment(issue *Issue) error {
 		return errNoCommentForContext
 	}
 
-	var err error
-	issue.Comment, err = buildComment(foundComments[0].Template, issue.CommVars)
-	return err
-}
-
-func buildComment(commentTemplate string, commentVars map[string]interface{}) (string, error) {
-	// Build comments with template args
-	t := template.New("comment template")
-
-	ct, err := t.Parse(commentTemplate)
-	if err != nil {
-		return "", err
-	}
-	var buf bytes.Buffer
-	if err = ct.Execute(&buf, commentVars); err != nil {
-		return "", err
+	var locale string
+	if b := r.baseTenet(); b.locale != nil {
+		locale = *b.locale
 	}
 
-	return buf.String(), nil
-}
+	// A comment in another language is no better than no comment.
+	comm := bestLocalized(foundComments, locale)
+	if comm == nil {
+		return errNoCommentForContext
+	}
 
-// returns the current file's context for this issue.
-func (r *review) currentFileContext(issueName string) CommentContext {
-	o := r.getIssueOrder()
-	fOrder := o.fileOrder[issueName][r.File().Filename()]
-	return fileContext[fOrder]
+	var err error
+	issue.Comment, err = r.baseTenet().buildComment(comm.Template, issue.CommVars)
+	return err
 }
@@ -0,0 +1,40 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"github.com/juju/errors"
+)
+
+// SeverityLevel is how important an issue is.
+type SeverityLevel string
+
+const (
+	SeverityError   SeverityLevel = "error"
+	SeverityWarning SeverityLevel = "warning"
+	SeverityInfo    SeverityLevel = "info"
+	SeverityHint    SeverityLevel = "hint"
+)
+
+// Severity returns a RegisterIssueOption which sets the severity of the
+// issue. Issues are registered with SeverityWarning by default. The user can
+// override the severity with the "severity.<issue_name>" option.
+func Severity(level SeverityLevel) RegisterIssueOption {
+	if err := validateSeverity(string(level)); err != nil {
+		// Yes panic, this is a developer error.
+		panic(err.Error())
+	}
+	return func(issue *Issue) {
+		issue.Severity = level
+	}
+}
+
+func validateSeverity(level string) error {
+	switch SeverityLevel(level) {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityHint:
+		return nil
+	}
+	return errors.Errorf("unknown severity %q, expected one of %q, %q, %q or %q",
+		level, SeverityError, SeverityWarning, SeverityInfo, SeverityHint)
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// suppressRegex matches a suppression directive in a comment. e.g.
+//
+// //lingo:ignore slasher/no_space_after_comment the url needs no space
+// //lingo:file-ignore license
+// //lingo:file-ignore
+//
+// The first group is the kind of directive, the second a comma separated list
+// of targets and the third, optional, the reason. A directive without targets
+// suppresses every issue.
+var suppressRegex = regexp.MustCompile(`(?://|#|/\*)\s*lingo:(ignore|file-ignore)(?:\s+(\S+)(?:\s+(.*))?)?\s*$`)
+
+// suppressions holds the suppression directives found in a file.
+type suppressions struct {
+	// lines maps a line number to the targets of the ignore directive on
+	// that line.
+	lines map[int][]string
+
+	// file holds the targets of all file-ignore directives in the file.
+	file []string
+}
+
+// parseSuppressions returns the suppression directives in the lines of a
+// text file, or nil if there are none.
+func parseSuppressions(lines [][]byte) *suppressions {
+	var s *suppressions
+	for i, line := range lines {
+		s = s.add(line, i+1)
+	}
+	return s
+}
+
+// parseCommentSuppressions returns the suppression directives in the
+// comments of a Go file, or nil if there are none. A directive in a string
+// literal is not a comment, so it is ignored.
+func parseCommentSuppressions(fset *token.FileSet, f *ast.File) *suppressions {
+	var s *suppressions
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			// Only the first line of a block comment is searched.
+			text := strings.SplitN(c.Text, "\n", 2)[0]
+			s = s.add([]byte(text), fset.PositionFor(c.Slash, false).Line)
+		}
+	}
+	return s
+}
+
+// add records the directive in text, found on line n, if there is one. It
+// returns s, or new suppressions if s is nil.
+func (s *suppressions) add(text []byte, n int) *suppressions {
+	if !bytes.Contains(text, []byte("lingo:")) {
+		return s
+	}
+	m := suppressRegex.FindSubmatch(text)
+	if m == nil {
+		return s
+	}
+	if s == nil {
+		s = &suppressions{lines: map[int][]string{}}
+	}
+	targets := []string{"*"}
+	if t := string(m[2]); t != "" && !strings.HasPrefix(t, "*/") {
+		targets = strings.Split(t, ",")
+	}
+	if string(m[1]) == "file-ignore" {
+		s.file = append(s.file, targets...)
+		return s
+	}
+	s.lines[n] = append(s.lines[n], targets...)
+	return s
+}
+
+// suppresses returns true if the named issue of the named tenet, raised on
+// line, is suppressed by a directive on the same line, on the line before or
+// by a file-ignore directive.
+func (s *suppressions) suppresses(tenetName, issueName string, line int) bool {
+	if s == nil {
+		return false
+	}
+	for _, targets := range [][]string{s.file, s.lines[line], s.lines[line-1]} {
+		for _, target := range targets {
+			if matchesTarget(target, tenetName, issueName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesTarget returns true if target is one of "*", "<tenet>", "<tenet>/*"
+// or "<tenet>/<issue>".
+func matchesTarget(target, tenetName, issueName string) bool {
+	if target == "*" {
+		return true
+	}
+	parts := strings.SplitN(target, "/", 2)
+	if parts[0] != tenetName {
+		return false
+	}
+	return len(parts) == 1 || parts[1] == "*" || parts[1] == issueName
+}
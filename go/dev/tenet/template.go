@@ -0,0 +1,152 @@
+// Copyright 2015 Jesse Meek.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tenet
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// templateFuncs are available in every comment template. e.g.
+//
+// {{quote .args | join ", "}} {{plural .args "isn't" "aren't"}} used.
+var templateFuncs = template.FuncMap{
+	"plural":   plural,
+	"join":     join,
+	"quote":    quote,
+	"code":     code,
+	"truncate": truncate,
+}
+
+// plural returns singular if n is one and plural otherwise. n is either a
+// number or something with a length, such as a slice.
+func plural(n interface{}, singular, plural string) (string, error) {
+	count, err := templateCount(n)
+	if err != nil {
+		return "", err
+	}
+	if count == 1 {
+		return singular, nil
+	}
+	return plural, nil
+}
+
+func templateCount(n interface{}) (int64, error) {
+	v := reflect.ValueOf(n)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return int64(v.Len()), nil
+	}
+	return 0, fmt.Errorf("plural expects a number or a list, got %T", n)
+}
+
+// join joins the elements of list, printed as with fmt.Sprint, with sep.
+func join(sep string, list interface{}) (string, error) {
+	elems, err := templateList("join", list)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(elems, sep), nil
+}
+
+// quote double quotes s, or each element if it is a list.
+func quote(s interface{}) (interface{}, error) {
+	if str, ok := s.(string); ok {
+		return strconv.Quote(str), nil
+	}
+	elems, err := templateList("quote", s)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range elems {
+		elems[i] = strconv.Quote(e)
+	}
+	return elems, nil
+}
+
+func templateList(name string, list interface{}) ([]string, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%s expects a list, got %T", name, list)
+	}
+	elems := make([]string, v.Len())
+	for i := range elems {
+		elems[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return elems, nil
+}
+
+// code returns the Go source of node, or node itself if it is a string,
+// formatted as inline code.
+func code(node interface{}) (string, error) {
+	var src string
+	switch n := node.(type) {
+	case string:
+		src = n
+	case ast.Node:
+		var buf bytes.Buffer
+		if err := format.Node(&buf, token.NewFileSet(), n); err != nil {
+			return "", err
+		}
+		src = buf.String()
+	default:
+		return "", fmt.Errorf("code expects an ast.Node or a string, got %T", node)
+	}
+	return "`" + src + "`", nil
+}
+
+// truncate shortens s to at most n characters, ending it with "..." if it
+// was cut.
+func truncate(n int, s string) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+// RegisterTemplateFunc adds a function which can be called from the
+// tenet's comment templates. It follows the rules of text/template's
+// FuncMap and replaces any built in function of the same name.
+func (b *Base) RegisterTemplateFunc(name string, fn interface{}) {
+	if b.templateFuncs == nil {
+		b.templateFuncs = template.FuncMap{}
+	}
+	b.templateFuncs[name] = fn
+}
+
+// parseComment parses a comment template with the template functions
+// available to the tenet.
+func (b *Base) parseComment(commentTemplate string) (*template.Template, error) {
+	return template.New("comment template").Funcs(templateFuncs).Funcs(b.templateFuncs).Parse(commentTemplate)
+}
+
+func (b *Base) buildComment(commentTemplate string, commentVars map[string]interface{}) (string, error) {
+	// Build comments with template args
+	ct, err := b.parseComment(commentTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = ct.Execute(&buf, commentVars); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
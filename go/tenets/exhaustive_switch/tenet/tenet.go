@@ -25,7 +25,7 @@ func New() *unusedArgTenet {
 
 	exhaustTag := t.RegisterOption("exhaust_tag", "lingo:exhaustive", "If this tag is found in a comment above a switch statement, that switch will be treated as an exhaustive switch.")
 	issue := t.RegisterIssue("missing_case",
-		tenet.AddComment(`The following cases are missing from this switch:{{.cases}}.`),
+		tenet.AddComment(`The following cases are missing from this switch: {{join ", " .cases}}.`),
 	)
 
 	// map of a switched variable to all case values.
@@ -148,12 +148,7 @@ func New() *unusedArgTenet {
 		switchLine := r.File().Fset().Position(swt.Pos()).Line
 
 		if cases, ok := missingCases[switchLine]; ok {
-			var missing string
-			for _, m := range cases {
-				missing += ", " + m.String()
-			}
-
-			r.RaiseNodeIssue(issue, swt, tenet.CommentVar("cases", missing[1:]))
+			r.RaiseNodeIssue(issue, swt, tenet.CommentVar("cases", cases))
 		}
 		return nil
 	})
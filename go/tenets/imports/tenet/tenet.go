@@ -5,9 +5,7 @@ package tenet
 
 import (
 	"go/ast"
-	"regexp"
 
-	"github.com/juju/errors"
 	"github.com/lingo-reviews/tenets/go/dev/tenet"
 )
 
@@ -25,7 +23,7 @@ func New() *importsTenet {
 		Language:    "go",
 	})
 
-	blacklisted := t.RegisterOption("blacklist_regex", "", "a regex to filter imports against")
+	blacklisted := t.RegisterRegexOption("blacklist_regex", "", "a regex to filter imports against")
 	issue := t.RegisterIssue("blacklisted_import",
 		tenet.AddComment(`This package should not be bringing in {{.importName}}`),
 	)
@@ -34,12 +32,7 @@ func New() *importsTenet {
 
 	t.SmellNode(func(r tenet.Review, imp *ast.ImportSpec) error {
 		importName := imp.Path.Value
-		m, err := regexp.MatchString(*blacklisted, importName)
-		if err != nil {
-			return errors.Trace(err)
-		}
-
-		if m {
+		if blacklisted.Load().MatchString(importName) {
 			r.RaiseNodeIssue(issue, imp, tenet.CommentVar("importName", importName))
 		}
 		return nil
@@ -6,6 +6,8 @@ package tenet_test
 import (
 	"testing"
 
+	"github.com/lingo-reviews/tenets/go/dev/api"
+	"github.com/lingo-reviews/tenets/go/dev/tenet"
 	tt "github.com/lingo-reviews/tenets/go/dev/tenet/testing"
 	gc "gopkg.in/check.v1"
 
@@ -45,3 +47,11 @@ func (s *importsSuite) TestExampleFiles(c *gc.C) {
 
 	s.CheckFiles(c, files, expectedIssues...)
 }
+
+func (s *importsSuite) TestBadBlacklistRegex(c *gc.C) {
+	err := s.Tenet.(tenet.BaseTenet).MixinConfigOptions([]*api.Option{{
+		Name:  "blacklist_regex",
+		Value: "(",
+	}})
+	c.Assert(err, gc.ErrorMatches, `invalid value for option "blacklist_regex": expected a regular expression, got "\(": .*\n(?s:.*)`)
+}
@@ -5,7 +5,6 @@ package tenet
 
 import (
 	"go/ast"
-	"strings"
 
 	"github.com/juju/errors"
 	"github.com/lingo-reviews/tenets/go/dev/tenet"
@@ -19,11 +18,12 @@ type assertLoopLenTenet struct {
 func New() *assertLoopLenTenet {
 	t := &assertLoopLenTenet{}
 	t.SetInfo(tenet.Info{
-		Name:        "juju_test_assert_loop_len",
-		Usage:       "If asserting within a loop, the length of the colleciton being iterated should be asserted",
-		Description: "If asserting within a loop, the length of the colleciton being iterated should be asserted",
-		SearchTags:  []string{"test", "loop"},
-		Language:    "go",
+		Name:         "juju_test_assert_loop_len",
+		Usage:        "If asserting within a loop, the length of the colleciton being iterated should be asserted",
+		Description:  "If asserting within a loop, the length of the colleciton being iterated should be asserted",
+		SearchTags:   []string{"test", "loop"},
+		Language:     "go",
+		IncludeFiles: []string{"*_test.go"},
 	})
 
 	assertLoopIssue := t.RegisterIssue("loop_len_not_asserted",
@@ -45,14 +45,6 @@ Here also, you can't gurantee the length of {{.looped}}'s result.`[1:], tenet.Se
 Again, need to assert result of {{.looped}} first.`[1:], tenet.DefaultComment),
 	)
 
-	// // First, knock out any file that isn't a test
-	t.SmellNode(func(r tenet.Review, _ *ast.File) error {
-		if !strings.HasSuffix(r.File().Filename(), "_test.go") {
-			r.FileDone()
-		}
-		return nil
-	})
-
 	// All nodes that have been asserted in a loop.
 	var ranged possibleBadRange
 
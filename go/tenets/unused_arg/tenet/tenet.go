@@ -2,7 +2,6 @@ package tenet
 
 import (
 	"go/ast"
-	"strings"
 
 	"github.com/lingo-reviews/tenets/go/dev/tenet"
 )
@@ -23,11 +22,10 @@ func New() *unusedArgTenet {
 
 	confidence := t.RegisterMetric("confidence")
 	issue := t.RegisterIssue("unused_func_arg",
-		// TODO(waigani) "Argument{s} {args} {is_are} not used in the function's body."
-		tenet.AddComment(`{{.args}} used. If this is intentional, please change the name to "_".`, tenet.FirstComment),
-		tenet.AddComment(`{{.args}} used. Please remove or set the name to "_".`, tenet.SecondComment),
-		tenet.AddComment(`{{.args}} used. Set to "_" or remove.`, tenet.ThirdComment),
-		tenet.AddComment(`{{.args}} used. You get the idea ...`, tenet.FourthComment),
+		tenet.AddComment(`{{quote .args | join ", "}} {{plural .args "isn't" "aren't"}} used. If this is intentional, please change the name to "_".`, tenet.FirstComment),
+		tenet.AddComment(`{{quote .args | join ", "}} {{plural .args "isn't" "aren't"}} used. Please remove or set the name to "_".`, tenet.SecondComment),
+		tenet.AddComment(`{{quote .args | join ", "}} {{plural .args "isn't" "aren't"}} used. Set to "_" or remove.`, tenet.ThirdComment),
+		tenet.AddComment(`{{quote .args | join ", "}} {{plural .args "isn't" "aren't"}} used. You get the idea ...`, tenet.FourthComment),
 	)
 
 	t.SmellNode(func(r tenet.Review, fnc *ast.FuncDecl) error {
@@ -49,16 +47,14 @@ func New() *unusedArgTenet {
 		}
 		ast.Walk(v, fnc.Body)
 		if len(v.args) > 0 {
-			names := make([]string, 0, len(v.args))
-			for k := range v.args {
-				names = append(names, k)
-			}
-			unused := `"` + strings.Join(names, `", "`)
-
-			if len(v.args) > 1 {
-				unused += `" aren't`
-			} else {
-				unused += `" isn't`
+			// List the unused args in the order they are declared.
+			var unused []string
+			for _, arg := range args {
+				for _, ident := range arg.Names {
+					if v.args[ident.Name] {
+						unused = append(unused, ident.Name)
+					}
+				}
 			}
 
 			r.RaiseNodeIssue(issue, fnc, confidence(0.5), tenet.CommentVar("args", unused))
@@ -19,7 +19,7 @@ DESCRIPTOR = _descriptor.FileDescriptor(
   name='api.proto',
   package='api',
   syntax='proto3',
-  serialized_pb=_b('\n\tapi.proto\x12\x03\x61pi\"\x05\n\x03Nil\"#\n\x04\x46ile\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05lines\x18\x02 \x03(\x03\"\xa3\x02\n\x05Issue\x12\x0c\n\x04name\x18\x01 \x01(\t\x12!\n\x08position\x18\x02 \x01(\x0b\x32\x0f.api.IssueRange\x12\x0f\n\x07\x63omment\x18\x03 \x01(\t\x12\x11\n\tctxBefore\x18\x04 \x01(\t\x12\x10\n\x08lineText\x18\x05 \x01(\t\x12\x10\n\x08\x63txAfter\x18\x06 \x01(\t\x12(\n\x07metrics\x18\x07 \x03(\x0b\x32\x17.api.Issue.MetricsEntry\x12\x0c\n\x04tags\x18\x08 \x03(\t\x12\x0c\n\x04link\x18\t \x01(\t\x12\x0f\n\x07newCode\x18\n \x01(\x08\x12\r\n\x05patch\x18\x0b \x01(\t\x12\x0b\n\x03\x65rr\x18\x0c \x01(\t\x1a.\n\x0cMetricsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"F\n\nIssueRange\x12\x1c\n\x05start\x18\x01 \x01(\x0b\x32\r.api.Position\x12\x1a\n\x03\x65nd\x18\x02 \x01(\x0b\x32\r.api.Position\"J\n\x08Position\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06Offset\x18\x02 \x01(\x03\x12\x0c\n\x04Line\x18\x03 \x01(\x03\x12\x0e\n\x06\x43olumn\x18\x04 \x01(\x03\"&\n\x06\x43onfig\x12\x1c\n\x07options\x18\x01 \x03(\x0b\x32\x0b.api.Option\"4\n\x06Option\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t\x12\r\n\x05usage\x18\x03 \x01(\t\"\x98\x01\n\x04Info\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05usage\x18\x02 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x03 \x01(\t\x12\x0f\n\x07version\x18\x04 \x01(\t\x12\x0c\n\x04tags\x18\x05 \x03(\t\x12\x0f\n\x07metrics\x18\x06 \x03(\t\x12\x10\n\x08language\x18\x07 \x01(\t\x12\x1c\n\x07options\x18\x08 \x03(\x0b\x32\x0b.api.Option\"$\n\rSchemaVersion\"\x13\n\x07version\x12\x08\n\x04V000\x10\x00\x32\xa4\x01\n\x05Tenet\x12%\n\x06Review\x12\t.api.File\x1a\n.api.Issue\"\x00(\x01\x30\x01\x12 \n\x07GetInfo\x12\x08.api.Nil\x1a\t.api.Info\"\x00\x12,\n\nAPIVersion\x12\x08.api.Nil\x1a\x12.api.SchemaVersion\"\x00\x12$\n\tConfigure\x12\x0b.api.Config\x1a\x08.api.Nil\"\x00\x42\x18\n\x10io.grpc.examples\xa2\x02\x03HLWb\x06proto3')
+  serialized_pb=_b('\n\tapi.proto\x12\x03\x61pi\"\x05\n\x03Nil\"j\n\x04\x46ile\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05lines\x18\x02 \x03(\x03\x12\x0c\n\x04\x64iff\x18\x03 \x01(\t\x12\x14\n\x0c\x62\x61se_content\x18\x04 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x05 \x01(\x0c\x12\x10\n\x08\x65ncoding\x18\x06 \x01(\t\"\xf7\x02\n\x05Issue\x12\x0c\n\x04name\x18\x01 \x01(\t\x12!\n\x08position\x18\x02 \x01(\x0b\x32\x0f.api.IssueRange\x12\x0f\n\x07\x63omment\x18\x03 \x01(\t\x12\x11\n\tctxBefore\x18\x04 \x01(\t\x12\x10\n\x08lineText\x18\x05 \x01(\t\x12\x10\n\x08\x63txAfter\x18\x06 \x01(\t\x12(\n\x07metrics\x18\x07 \x03(\x0b\x32\x17.api.Issue.MetricsEntry\x12\x0c\n\x04tags\x18\x08 \x03(\t\x12\x0c\n\x04link\x18\t \x01(\t\x12\x0f\n\x07newCode\x18\n \x01(\x08\x12\r\n\x05patch\x18\x0b \x01(\t\x12\x0b\n\x03\x65rr\x18\x0c \x01(\t\x12\x17\n\x05\x66ixes\x18\r \x03(\x0b\x32\x08.api.Fix\x12\x12\n\nsuppressed\x18\x0e \x01(\x08\x12\x13\n\x0b\x66ingerprint\x18\x0f \x01(\t\x12\x10\n\x08severity\x18\x10 \x01(\t\x1a.\n\x0cMetricsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"1\n\x03\x46ix\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x1c\n\x05\x65\x64its\x18\x02 \x03(\x0b\x32\r.api.TextEdit\"V\n\x08TextEdit\x12\x1c\n\x05start\x18\x01 \x01(\x0b\x32\r.api.Position\x12\x1a\n\x03\x65nd\x18\x02 \x01(\x0b\x32\r.api.Position\x12\x10\n\x08new_text\x18\x03 \x01(\t\"F\n\nIssueRange\x12\x1c\n\x05start\x18\x01 \x01(\x0b\x32\r.api.Position\x12\x1a\n\x03\x65nd\x18\x02 \x01(\x0b\x32\r.api.Position\"J\n\x08Position\x12\x10\n\x08\x66ilename\x18\x01 \x01(\t\x12\x0e\n\x06Offset\x18\x02 \x01(\x03\x12\x0c\n\x04Line\x18\x03 \x01(\x03\x12\x0e\n\x06\x43olumn\x18\x04 \x01(\x03\"&\n\x06\x43onfig\x12\x1c\n\x07options\x18\x01 \x03(\x0b\x32\x0b.api.Option\"4\n\x06Option\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t\x12\r\n\x05usage\x18\x03 \x01(\t\"\xbd\x01\n\x04Info\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05usage\x18\x02 \x01(\t\x12\x13\n\x0b\x64\x65scription\x18\x03 \x01(\t\x12\x0f\n\x07version\x18\x04 \x01(\t\x12\x0c\n\x04tags\x18\x05 \x03(\t\x12\x0f\n\x07metrics\x18\x06 \x03(\t\x12\x10\n\x08language\x18\x07 \x01(\t\x12\x1c\n\x07options\x18\x08 \x03(\x0b\x32\x0b.api.Option\x12\x0f\n\x07locales\x18\t \x03(\t\x12\x12\n\nextensions\x18\n \x03(\t\"$\n\rSchemaVersion\"\x13\n\x07version\x12\x08\n\x04V000\x10\x00\x32\xa4\x01\n\x05Tenet\x12%\n\x06Review\x12\t.api.File\x1a\n.api.Issue\"\x00(\x01\x30\x01\x12 \n\x07GetInfo\x12\x08.api.Nil\x1a\t.api.Info\"\x00\x12,\n\nAPIVersion\x12\x08.api.Nil\x1a\x12.api.SchemaVersion\"\x00\x12$\n\tConfigure\x12\x0b.api.Config\x1a\x08.api.Nil\"\x00\x42\x18\n\x10io.grpc.examples\xa2\x02\x03HLWb\x06proto3')
 )
 _sym_db.RegisterFileDescriptor(DESCRIPTOR)
 
@@ -38,8 +38,8 @@ _SCHEMAVERSION_VERSION = _descriptor.EnumDescriptor(
   ],
   containing_type=None,
   options=None,
-  serialized_start=770,
-  serialized_end=789,
+  serialized_start=1101,
+  serialized_end=1120,
 )
 _sym_db.RegisterEnumDescriptor(_SCHEMAVERSION_VERSION)
 
@@ -89,6 +89,34 @@ _FILE = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
+    _descriptor.FieldDescriptor(
+      name='diff', full_name='api.File.diff', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='base_content', full_name='api.File.base_content', index=3,
+      number=4, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='content', full_name='api.File.content', index=4,
+      number=5, type=12, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b(""),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='encoding', full_name='api.File.encoding', index=5,
+      number=6, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
   ],
   extensions=[
   ],
@@ -102,7 +130,7 @@ _FILE = _descriptor.Descriptor(
   oneofs=[
   ],
   serialized_start=25,
-  serialized_end=60,
+  serialized_end=131,
 )
 
 
@@ -139,8 +167,8 @@ _ISSUE_METRICSENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=308,
-  serialized_end=354,
+  serialized_start=463,
+  serialized_end=509,
 )
 
 _ISSUE = _descriptor.Descriptor(
@@ -234,6 +262,34 @@ _ISSUE = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
+    _descriptor.FieldDescriptor(
+      name='fixes', full_name='api.Issue.fixes', index=12,
+      number=13, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='suppressed', full_name='api.Issue.suppressed', index=13,
+      number=14, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='fingerprint', full_name='api.Issue.fingerprint', index=14,
+      number=15, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='severity', full_name='api.Issue.severity', index=15,
+      number=16, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
   ],
   extensions=[
   ],
@@ -246,8 +302,91 @@ _ISSUE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=63,
-  serialized_end=354,
+  serialized_start=134,
+  serialized_end=509,
+)
+
+
+_FIX = _descriptor.Descriptor(
+  name='Fix',
+  full_name='api.Fix',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='name', full_name='api.Fix.name', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='edits', full_name='api.Fix.edits', index=1,
+      number=2, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=511,
+  serialized_end=560,
+)
+
+
+_TEXTEDIT = _descriptor.Descriptor(
+  name='TextEdit',
+  full_name='api.TextEdit',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='start', full_name='api.TextEdit.start', index=0,
+      number=1, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='end', full_name='api.TextEdit.end', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='new_text', full_name='api.TextEdit.new_text', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=562,
+  serialized_end=648,
 )
 
 
@@ -284,8 +423,8 @@ _ISSUERANGE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=356,
-  serialized_end=426,
+  serialized_start=650,
+  serialized_end=720,
 )
 
 
@@ -336,8 +475,8 @@ _POSITION = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=428,
-  serialized_end=502,
+  serialized_start=722,
+  serialized_end=796,
 )
 
 
@@ -367,8 +506,8 @@ _CONFIG = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=504,
-  serialized_end=542,
+  serialized_start=798,
+  serialized_end=836,
 )
 
 
@@ -412,8 +551,8 @@ _OPTION = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=544,
-  serialized_end=596,
+  serialized_start=838,
+  serialized_end=890,
 )
 
 
@@ -480,6 +619,20 @@ _INFO = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None),
+    _descriptor.FieldDescriptor(
+      name='locales', full_name='api.Info.locales', index=8,
+      number=9, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
+    _descriptor.FieldDescriptor(
+      name='extensions', full_name='api.Info.extensions', index=9,
+      number=10, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None),
   ],
   extensions=[
   ],
@@ -492,8 +645,8 @@ _INFO = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=599,
-  serialized_end=751,
+  serialized_start=893,
+  serialized_end=1082,
 )
 
 
@@ -517,13 +670,17 @@ _SCHEMAVERSION = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=753,
-  serialized_end=789,
+  serialized_start=1084,
+  serialized_end=1120,
 )
 
 _ISSUE_METRICSENTRY.containing_type = _ISSUE
 _ISSUE.fields_by_name['position'].message_type = _ISSUERANGE
 _ISSUE.fields_by_name['metrics'].message_type = _ISSUE_METRICSENTRY
+_ISSUE.fields_by_name['fixes'].message_type = _FIX
+_FIX.fields_by_name['edits'].message_type = _TEXTEDIT
+_TEXTEDIT.fields_by_name['start'].message_type = _POSITION
+_TEXTEDIT.fields_by_name['end'].message_type = _POSITION
 _ISSUERANGE.fields_by_name['start'].message_type = _POSITION
 _ISSUERANGE.fields_by_name['end'].message_type = _POSITION
 _CONFIG.fields_by_name['options'].message_type = _OPTION
@@ -532,6 +689,8 @@ _SCHEMAVERSION_VERSION.containing_type = _SCHEMAVERSION
 DESCRIPTOR.message_types_by_name['Nil'] = _NIL
 DESCRIPTOR.message_types_by_name['File'] = _FILE
 DESCRIPTOR.message_types_by_name['Issue'] = _ISSUE
+DESCRIPTOR.message_types_by_name['Fix'] = _FIX
+DESCRIPTOR.message_types_by_name['TextEdit'] = _TEXTEDIT
 DESCRIPTOR.message_types_by_name['IssueRange'] = _ISSUERANGE
 DESCRIPTOR.message_types_by_name['Position'] = _POSITION
 DESCRIPTOR.message_types_by_name['Config'] = _CONFIG
@@ -568,6 +727,20 @@ Issue = _reflection.GeneratedProtocolMessageType('Issue', (_message.Message,), d
 _sym_db.RegisterMessage(Issue)
 _sym_db.RegisterMessage(Issue.MetricsEntry)
 
+Fix = _reflection.GeneratedProtocolMessageType('Fix', (_message.Message,), dict(
+  DESCRIPTOR = _FIX,
+  __module__ = 'api_pb2'
+  # @@protoc_insertion_point(class_scope:api.Fix)
+  ))
+_sym_db.RegisterMessage(Fix)
+
+TextEdit = _reflection.GeneratedProtocolMessageType('TextEdit', (_message.Message,), dict(
+  DESCRIPTOR = _TEXTEDIT,
+  __module__ = 'api_pb2'
+  # @@protoc_insertion_point(class_scope:api.TextEdit)
+  ))
+_sym_db.RegisterMessage(TextEdit)
+
 IssueRange = _reflection.GeneratedProtocolMessageType('IssueRange', (_message.Message,), dict(
   DESCRIPTOR = _ISSUERANGE,
   __module__ = 'api_pb2'